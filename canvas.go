@@ -9,6 +9,15 @@ import (
 	"strings"
 )
 
+// CanvasMode is the render mode of a Canvas
+// Canvas_Aspect renders each character space as 1x2 pixels, Canvas_Dense as 2x2 pixels
+type CanvasMode int
+
+const (
+	Canvas_Aspect CanvasMode = iota
+	Canvas_Dense
+)
+
 // Canvas holds a bit buffer for plotting
 // Canvas assumes a virtual canvas of bits arranged as
 // width 0 ->
@@ -144,15 +153,7 @@ func (c *Canvas) Clear() {
 
 // Render the Canvas to a string as "square" (1x2) pixels
 func (c *Canvas) StringAspect() string {
-	var str string
-	for y := 0; y < c.bheight; y++ {
-		for x := 0; x < c.bwidth; x++ {
-			index := y*c.bwidth + x
-			str += fmt.Sprintf("%c%c", BlocksAspect[(*c.value)[index]][0], BlocksAspect[(*c.value)[index]][1])
-		}
-		str += "\r\n"
-	}
-	return str
+	return strings.Join(c.Rows(Canvas_Aspect), "\r\n") + "\r\n"
 }
 
 // Render the Canvas to a string as "square" (1x2) pixels, with a border
@@ -177,15 +178,55 @@ func (c *Canvas) StringAspectBorder() string {
 
 // Render the Canvas to a string as dense (2x2) rectangle pixels
 func (c *Canvas) StringDense() string {
-	var str string
-	for y := 0; y < c.bheight; y++ {
+	return strings.Join(c.Rows(Canvas_Dense), "\r\n") + "\r\n"
+}
+
+// Rows renders the Canvas as rows of characters in a render mode, without line endings
+func (c *Canvas) Rows(mode CanvasMode) []string {
+	rows := make([]string, c.bheight)
+	for y := range rows {
+		var b strings.Builder
 		for x := 0; x < c.bwidth; x++ {
-			index := y*c.bwidth + x
-			str += fmt.Sprintf("%c", BlocksDense[(*c.value)[index]])
+			v := (*c.value)[y*c.bwidth+x]
+			if mode == Canvas_Dense {
+				b.WriteRune(rune(BlocksDense[v]))
+			} else {
+				b.WriteRune(rune(BlocksAspect[v][0]))
+				b.WriteRune(rune(BlocksAspect[v][1]))
+			}
 		}
-		str += "\r\n"
+		rows[y] = b.String()
+	}
+	return rows
+}
+
+// Copy copies the pixels of src that fit onto the Canvas, replacing them
+func (c *Canvas) Copy(src *Canvas) {
+	xor := c.xor
+	c.xor = false
+	for y := 0; y < c.pheight && y < src.pheight; y++ {
+		for x := 0; x < c.pwidth && x < src.pwidth; x++ {
+			if src.Read(x, y) != c.Read(x, y) {
+				c.xor = true
+				c.Plot(x, y)
+				c.xor = false
+			}
+		}
+	}
+	c.xor = xor
+}
+
+// Resize changes the size of the Canvas, in pixels, keeping the pixels that still fit and the plot modes
+func (c *Canvas) Resize(w, h int) {
+	if w == c.pwidth && h == c.pheight {
+		return
+	}
+	old := *c
+	c.Init(w, h)
+	c.xor, c.wrap = old.xor, old.wrap
+	if old.value != nil {
+		c.Copy(&old)
 	}
-	return str
 }
 
 // Render the Canvas to a string as dense (2x2) rectangle pixels, with a border
@@ -0,0 +1,116 @@
+package termfun
+
+// canvastile.go implements TileType_Canvas, a tile that owns a Canvas sized to the tile.
+// The Canvas is resized, keeping its pixels, whenever the tiles are laid out again.
+
+import (
+	"errors"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// canvasState holds the state of a TileType_Canvas tile
+type canvasState struct {
+	canvas *Canvas
+	mode   CanvasMode
+}
+
+// Canvas returns the Canvas of a TileType_Canvas tile, sized to the tile for its render mode, or nil
+// The Canvas is resized in place, under the tile lock, when the tiles are laid out, so only read or draw on it
+// in a DrawCanvas function while the session renders, or the drawing can race the resize
+func (t *Tile) Canvas() *Canvas {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Canvas {
+		return nil
+	}
+	t.canvasFit()
+	return t.canvas.canvas
+}
+
+// DrawCanvas calls draw with the Canvas of a TileType_Canvas tile, sized to the tile, holding the tile lock
+// so the Canvas is not resized while draw runs. draw must not call methods of the tile.
+func (t *Tile) DrawCanvas(draw func(c *Canvas)) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Canvas {
+		return errors.New("Handler.TileType does not support DrawCanvas")
+	}
+	t.canvasFit()
+	draw(t.canvas.canvas)
+	t.dirty = true
+	return nil
+}
+
+// SetCanvasMode sets the render mode of a TileType_Canvas tile, Canvas_Aspect by default, resizing the Canvas to match
+func (t *Tile) SetCanvasMode(mode CanvasMode) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Canvas {
+		return errors.New("Handler.TileType does not support CanvasMode")
+	}
+	t.canvas.mode = mode
+	t.canvasFit()
+	t.dirty = true
+	return nil
+}
+
+// canvasSize returns the Canvas size in pixels that fills the tile in the render mode
+func (t *Tile) canvasSize() (int, int) {
+	w, h := t.Width(), t.Height()*2
+	if t.canvas.mode == Canvas_Dense {
+		w *= 2
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return w, h
+}
+
+// canvasFit creates or resizes the Canvas to fill the tile
+func (t *Tile) canvasFit() {
+	w, h := t.canvasSize()
+	if t.canvas.canvas == nil {
+		t.canvas.canvas = NewCanvas(w, h)
+		return
+	}
+	t.canvas.canvas.Resize(w, h)
+}
+
+// == TileType_Canvas Handler Functions
+func cv_RenderText(t *Tile) string {
+	t.lock.Lock()
+	t.canvasFit()
+	rows := t.canvas.canvas.Rows(t.canvas.mode)
+	t.lock.Unlock()
+	var str string
+	for y := 0; y < t.Height(); y++ {
+		str += CUP(t.bounds.Min.X, t.bounds.Min.Y+y)
+		if y < len(rows) {
+			str += format.FormatTextClipCol(rows[y], t.Width(), 1, 0)[0]
+		} else {
+			str += padSpaces(t.Width())
+		}
+	}
+	sd_SetCurPosOrigin(t)
+	return str
+}
+
+// cv_CopyLines returns the rows of the Canvas
+func cv_CopyLines(t *Tile) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.canvasFit()
+	return t.canvas.canvas.Rows(t.canvas.mode)
+}
+
+// cv_KeyPress passes keys to the KeyCallback, if any, see Tile.SetKeyCallback
+func cv_KeyPress(t *Tile, r rune) bool {
+	if t.keyCallback != nil {
+		return t.keyCallback(r)
+	}
+	return false
+}
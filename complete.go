@@ -0,0 +1,158 @@
+package termfun
+
+// complete.go supports tab completion for tiles with an input line, like TileType_ScrollUp
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// CompletionCallback returns the candidates to complete the line at byte offset pos,
+// and the byte offset start of the text in line that a candidate replaces
+type CompletionCallback func(line string, pos int) (start int, candidates []string)
+
+// maxPopupRows is the most items shown at once in a popup list, like the completion candidates
+const maxPopupRows = 8
+
+// complete handles KeyTab and KeyBackTab for completion.
+// The first KeyTab inserts the common prefix of the candidates and shows them,
+// following KeyTab or KeyBackTab cycle through them.
+func (t *Tile) complete(r rune) {
+	if len(t.completions) > 1 { // cycle
+		n := len(t.completions)
+		if r == KeyBackTab {
+			t.compIndex = (t.compIndex - 1 + n) % n
+		} else {
+			t.compIndex = (t.compIndex + 1) % n
+		}
+		t.completeReplace(t.completions[t.compIndex])
+		return
+	}
+	if r != KeyTab {
+		return
+	}
+	start, candidates := t.completionCallback(t.line, t.linePos)
+	if start < 0 || start > t.linePos || len(candidates) == 0 {
+		return
+	}
+	t.compStart = start
+	if len(candidates) == 1 {
+		t.completeReplace(candidates[0])
+		return
+	}
+	t.completions = candidates
+	t.compIndex = -1
+	if prefix := commonPrefix(candidates); len(prefix) > t.linePos-start {
+		t.completeReplace(prefix)
+	}
+}
+
+// completeReplace replaces the text being completed with s
+func (t *Tile) completeReplace(s string) {
+	t.lineDelete(t.compStart, t.linePos)
+	t.lineInsert(s)
+}
+
+// completeClear hides the completion popup
+func (t *Tile) completeClear() {
+	t.completions = nil
+	t.compIndex = -1
+}
+
+// renderPopup renders a popup list of items above the input line, with the selected item, or -1, highlighted
+// top is the screen row of the first row of the input line, x the column to start the popup
+func (t *Tile) renderPopup(items []string, selected int, top, x int) string {
+	rows := len(items)
+	if rows > maxPopupRows {
+		rows = maxPopupRows
+	}
+	if rows > top-t.bounds.Min.Y {
+		rows = top - t.bounds.Min.Y
+	}
+	if rows < 1 {
+		return ""
+	}
+	var w int
+	for _, c := range items {
+		if cw := format.StringWidth(c) + 2; cw > w {
+			w = cw
+		}
+	}
+	if w > t.Width() {
+		w = t.Width()
+	}
+	if x+w-1 > t.bounds.Max.X {
+		x = t.bounds.Max.X - w + 1
+	}
+	first := 0 // scroll so the selected item is visible
+	if selected >= rows {
+		first = selected - rows + 1
+	}
+	var str string
+	for i := 0; i < rows; i++ {
+		c := items[first+i]
+		item := format.FormatTextClipCol(" "+c+" ", w, 1, 0)[0]
+		str += CUP(x, top-rows+i)
+		if first+i == selected {
+			str += SGR(SGR_Negative, SGR_Bold) + item + SGR(SGR_Off)
+		} else {
+			str += SGR(SGR_Negative) + item + SGR(SGR_Off)
+		}
+	}
+	return str
+}
+
+// commonPrefix returns the longest common prefix of ss, on a rune boundary
+func commonPrefix(ss []string) string {
+	prefix := ss[0]
+	for _, s := range ss[1:] {
+		i := 0
+		for i < len(prefix) && i < len(s) && prefix[i] == s[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	for len(prefix) > 0 && !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
+
+// FileCompleter is a CompletionCallback that completes the file path before pos.
+// Directories end in "/", and hidden files are only offered if the name starts with ".".
+func FileCompleter(line string, pos int) (int, []string) {
+	start := strings.LastIndexAny(line[:pos], " \t") + 1
+	word := line[start:pos]
+	dir, base := filepath.Split(word)
+	readDir := dir
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			readDir = filepath.Join(home, dir[2:])
+		}
+	}
+	if readDir == "" {
+		readDir = "."
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return start, nil
+	}
+	var candidates []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		if e.IsDir() {
+			name += "/"
+		}
+		candidates = append(candidates, dir+name)
+	}
+	sort.Strings(candidates)
+	return start, candidates
+}
@@ -0,0 +1,297 @@
+package termfun
+
+// copy.go supports copy mode, like tmux, to select and copy text out of any tile.
+// The copy key, CtrlX by default, freezes the focus tile text and shows a cursor in it.
+// The arrow keys move the cursor, v starts a character selection, V a line selection
+// and CtrlV a block selection, shown in reverse video. y or Enter yanks the selection,
+// q or Escape leaves copy mode.
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// defaultCopyKey enters copy mode for the focus tile, see TileTerm.SetCopyKey
+const defaultCopyKey = CtrlX
+
+// YankCallback receives the text yanked in copy mode
+type YankCallback func(text string)
+
+// SelectType is the kind of copy mode selection
+type SelectType int
+
+const (
+	Select_None SelectType = iota
+	Select_Char
+	Select_Line
+	Select_Block
+)
+
+// copyState holds the copy mode state of a tile
+type copyState struct {
+	active bool
+	lines  []string   // text lines frozen when copy mode started
+	view   Point      // top left of the view in lines
+	cursor Point      // cursor in lines, column and line
+	anchor Point      // selection start
+	sel    SelectType // selection kind, or Select_None
+}
+
+// SetCopyKey sets the key that enters copy mode for the focus tile, or 0 to disable copy mode
+func (tTerm *TileTerm) SetCopyKey(r rune) {
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	tTerm.keyCopy = r
+}
+
+// SetYankCallback sets the function that receives text yanked in copy mode
+// If osc52 is true, the text is also sent to the terminal clipboard with OSC 52
+func (tTerm *TileTerm) SetYankCallback(c YankCallback, osc52 bool) {
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	tTerm.yankCallback = c
+	tTerm.osc52 = osc52
+}
+
+// OSC52 returns the OSC 52 sequence that sets the terminal clipboard to text
+func OSC52(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
+
+// yank delivers text yanked in copy mode
+func (tTerm *TileTerm) yank(text string) {
+	if tTerm.yankCallback != nil {
+		tTerm.yankCallback(text)
+	}
+	if tTerm.osc52 { // not in the middle of a frame
+		tTerm.renderLock.Lock()
+		fmt.Fprint(tTerm.out, OSC52(text))
+		tTerm.renderLock.Unlock()
+	}
+}
+
+// copyStart enters copy mode, freezing the tile text as lines from the handler CopyLines, or the tile Buffer
+func (t *Tile) copyStart() {
+	var lines []string
+	if t.handler.CopyLines != nil {
+		lines = t.handler.CopyLines(t)
+	} else {
+		lines = format.SplitLines(stripCSI(t.Buffer()), t.tabs)
+	}
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	c := copyState{active: true, lines: lines}
+	if t.scrollable() {
+		c.view = t.start
+	} else if len(lines) > t.Height() {
+		c.view.Y = len(lines) - t.Height()
+	}
+	c.cursor = Point{X: c.view.X, Y: c.view.Y + t.Height() - 1}
+	if c.cursor.Y >= len(lines) {
+		c.cursor.Y = len(lines) - 1
+	}
+	t.copy = c
+	t.setDirty()
+}
+
+// copyKey handles a key in copy mode, returning the yanked text and true when copy mode ends
+func (t *Tile) copyKey(r rune) (string, bool) {
+	c := &t.copy
+	defer t.setDirty()
+	switch r {
+	case KeyUp:
+		c.cursor.Y--
+	case KeyDown:
+		c.cursor.Y++
+	case KeyLeft:
+		c.cursor.X--
+	case KeyRight:
+		c.cursor.X++
+	case KeyPgUp:
+		c.cursor.Y -= t.Height()
+	case KeyPgDn:
+		c.cursor.Y += t.Height()
+	case KeyHome:
+		c.cursor.X = 0
+	case KeyEnd:
+		if c.cursor.Y >= 0 && c.cursor.Y < len(c.lines) {
+			c.cursor.X = len([]rune(c.lines[c.cursor.Y])) - 1
+		}
+	case 'g':
+		c.cursor = Point{}
+	case 'G':
+		c.cursor = Point{Y: len(c.lines) - 1}
+	case 'v', KeySpace:
+		c.selectToggle(Select_Char)
+	case 'V':
+		c.selectToggle(Select_Line)
+	case CtrlV:
+		c.selectToggle(Select_Block)
+	case 'y', KeyEnter:
+		text := c.selection()
+		t.copy = copyState{}
+		return text, true
+	case 'q', KeyEscape, CtrlG:
+		t.copy = copyState{}
+		return "", true
+	}
+	c.clamp(t.Width(), t.Height())
+	return "", false
+}
+
+// selectToggle starts a selection of kind sel at the cursor, or changes its kind, or ends it
+func (c *copyState) selectToggle(sel SelectType) {
+	switch c.sel {
+	case Select_None:
+		c.anchor = c.cursor
+		c.sel = sel
+	case sel:
+		c.sel = Select_None
+	default:
+		c.sel = sel
+	}
+}
+
+// clamp keeps the cursor in the lines and the view around the cursor
+func (c *copyState) clamp(w, h int) {
+	if c.cursor.Y >= len(c.lines) {
+		c.cursor.Y = len(c.lines) - 1
+	}
+	if c.cursor.Y < 0 {
+		c.cursor.Y = 0
+	}
+	if c.cursor.X < 0 {
+		c.cursor.X = 0
+	}
+	if c.cursor.Y < c.view.Y {
+		c.view.Y = c.cursor.Y
+	}
+	if c.cursor.Y >= c.view.Y+h {
+		c.view.Y = c.cursor.Y - h + 1
+	}
+	if c.cursor.X < c.view.X {
+		c.view.X = c.cursor.X
+	}
+	if c.cursor.X >= c.view.X+w {
+		c.view.X = c.cursor.X - w + 1
+	}
+}
+
+// bounds returns the selection start and end in order, by line then column
+func (c *copyState) bounds() (Point, Point) {
+	a, b := c.anchor, c.cursor
+	if b.Y < a.Y || b.Y == a.Y && b.X < a.X {
+		a, b = b, a
+	}
+	return a, b
+}
+
+// selected returns true if column x of line y is selected
+func (c *copyState) selected(x, y int) bool {
+	a, b := c.bounds()
+	switch c.sel {
+	case Select_Char:
+		return (y > a.Y || y == a.Y && x >= a.X) && (y < b.Y || y == b.Y && x <= b.X)
+	case Select_Line:
+		return y >= a.Y && y <= b.Y
+	case Select_Block:
+		x0, x1 := c.anchor.X, c.cursor.X
+		if x1 < x0 {
+			x0, x1 = x1, x0
+		}
+		return y >= a.Y && y <= b.Y && x >= x0 && x <= x1
+	}
+	return false
+}
+
+// selection returns the selected text, or the cursor line if there is no selection
+func (c *copyState) selection() string {
+	if len(c.lines) == 0 {
+		return ""
+	}
+	if c.sel == Select_None {
+		return c.lines[c.cursor.Y]
+	}
+	a, b := c.bounds()
+	var out []string
+	for y := a.Y; y <= b.Y; y++ {
+		var line strings.Builder
+		for x, r := range []rune(c.lines[y]) {
+			if c.selected(x, y) {
+				line.WriteRune(r)
+			}
+		}
+		out = append(out, strings.TrimRight(line.String(), " "))
+	}
+	return strings.Join(out, "\n")
+}
+
+// copyRender renders the frozen lines with the selection in reverse video and places the cursor
+func (t *Tile) copyRender() string {
+	c := &t.copy
+	c.clamp(t.Width(), t.Height())
+	var str string
+	for row := 0; row < t.Height(); row++ {
+		y := c.view.Y + row
+		var rs []rune
+		if y < len(c.lines) {
+			rs = []rune(c.lines[y])
+		}
+		str += CUP(t.bounds.Min.X, t.bounds.Min.Y+row)
+		on := false
+		for col := 0; col < t.Width(); col++ {
+			x := c.view.X + col
+			r := ' '
+			if x < len(rs) {
+				r = rs[x]
+			}
+			if sel := c.selected(x, y); sel != on {
+				if sel {
+					str += SGR(SGR_Negative)
+				} else {
+					str += SGR(SGR_Off)
+				}
+				on = sel
+			}
+			str += string(r)
+		}
+		if on {
+			str += SGR(SGR_Off)
+		}
+	}
+	t.curPos = Point{X: t.bounds.Min.X + c.cursor.X - c.view.X, Y: t.bounds.Min.Y + c.cursor.Y - c.view.Y}
+	return str
+}
+
+// copyStatus returns the copy mode status for the bottom outline, or ""
+func (t *Tile) copyStatus() string {
+	c := &t.copy
+	if !c.active {
+		return ""
+	}
+	mode := [...]string{"copy", "copy char", "copy line", "copy block"}[c.sel]
+	return fmt.Sprintf("%s %d,%d", mode, c.cursor.Y+1, c.cursor.X+1)
+}
+
+// stripCSI removes CSI escape sequences, such as SGR styles, from text
+func stripCSI(text string) string {
+	if !strings.Contains(text, "\x1b[") {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		j := format.NextGrapheme(text, i)
+		if !strings.HasPrefix(text[i:j], "\x1b[") {
+			b.WriteString(text[i:j])
+		}
+		i = j
+	}
+	return b.String()
+}
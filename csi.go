@@ -93,15 +93,38 @@ type SGRType int
 const (
 	SGR_Off          SGRType = 0  // All attributes off
 	SGR_Bold         SGRType = 1  // Bold
+	SGR_Faint        SGRType = 2  // Faint
+	SGR_Italic       SGRType = 3  // Italic
 	SGR_Underline    SGRType = 4  // Underline
 	SGR_Blinking     SGRType = 5  // Blinking
 	SGR_Negative     SGRType = 7  // Negative image
 	SGR_Invisible    SGRType = 8  // Invisible image
 	SGR_BoldOff      SGRType = 22 // Bold off
+	SGR_ItalicOff    SGRType = 23 // Italic off
 	SGR_UnderlineOff SGRType = 24 // Underline off
 	SGR_BlinkingOff  SGRType = 25 // Blinking off
 	SGR_NegativeOff  SGRType = 27 // Negative image off
 	SGR_InvisibleOff SGRType = 28 // Invisible image off
+
+	SGR_FgBlack   SGRType = 30 // Foreground colors
+	SGR_FgRed     SGRType = 31
+	SGR_FgGreen   SGRType = 32
+	SGR_FgYellow  SGRType = 33
+	SGR_FgBlue    SGRType = 34
+	SGR_FgMagenta SGRType = 35
+	SGR_FgCyan    SGRType = 36
+	SGR_FgWhite   SGRType = 37
+	SGR_FgDefault SGRType = 39
+
+	SGR_BgBlack   SGRType = 40 // Background colors
+	SGR_BgRed     SGRType = 41
+	SGR_BgGreen   SGRType = 42
+	SGR_BgYellow  SGRType = 43
+	SGR_BgBlue    SGRType = 44
+	SGR_BgMagenta SGRType = 45
+	SGR_BgCyan    SGRType = 46
+	SGR_BgWhite   SGRType = 47
+	SGR_BgDefault SGRType = 49
 )
 
 // SGR - Select Graphic Rendition, other data may follow
@@ -0,0 +1,94 @@
+package termfun
+
+// dialog.go implements modal alert, confirm and prompt dialogs as centered floating TileType_Form tiles.
+// A dialog takes the focus until it is dismissed, then calls back with the answer.
+
+import (
+	"strings"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// dialog sizes including the outline, a dialog grows from dialogMinWidth up to dialogMaxWidth to fit its message
+const (
+	dialogMinWidth = 24
+	dialogMaxWidth = 60
+)
+
+// Alert shows a modal dialog with message and an OK button, done is called when it is dismissed, or nil
+// Returning true from done exits TileTerm
+func (tTerm *TileTerm) Alert(title, message string, done func() bool) (*Tile, error) {
+	return tTerm.dialog(title, message, nil, []string{"OK"}, func(button string, values map[string]string) bool {
+		if done == nil {
+			return false
+		}
+		return done()
+	})
+}
+
+// Confirm shows a modal dialog with message and OK and Cancel buttons, done is called with true for OK, or nil
+// Returning true from done exits TileTerm
+func (tTerm *TileTerm) Confirm(title, message string, done func(ok bool) bool) (*Tile, error) {
+	return tTerm.dialog(title, message, nil, []string{"OK", "Cancel"}, func(button string, values map[string]string) bool {
+		if done == nil {
+			return false
+		}
+		return done(button == "ok")
+	})
+}
+
+// Prompt shows a modal dialog with message, a text field holding value, and OK and Cancel buttons,
+// done is called with the text and true for OK, or nil. Returning true from done exits TileTerm
+func (tTerm *TileTerm) Prompt(title, message, value string, done func(value string, ok bool) bool) (*Tile, error) {
+	input := &FormField{Type: Field_Text, Name: "value", Value: value}
+	return tTerm.dialog(title, message, input, []string{"OK", "Cancel"}, func(button string, values map[string]string) bool {
+		if done == nil {
+			return false
+		}
+		return done(values["value"], button == "ok")
+	})
+}
+
+// dialog shows a centered modal form with the message, an optional input field and a row of buttons,
+// then deletes it and calls done with the name of the button, the label in lower case, or "cancel" for KeyEscape
+func (tTerm *TileTerm) dialog(title, message string, input *FormField, buttons []string, done SubmitCallback) (*Tile, error) {
+	width := dialogMinWidth
+	for _, line := range strings.Split(message, "\n") {
+		if w := format.StringWidth(line) + 2; w > width {
+			width = w
+		}
+	}
+	if width > dialogMaxWidth {
+		width = dialogMaxWidth
+	}
+	if w, _ := tTerm.getSize(); w > 0 && width > w { // known after the first Render
+		width = w
+	}
+	var fields []FormField
+	for _, line := range format.FormatTextBreak(strings.TrimRight(message, "\n"), width-2, defaultTabSize) {
+		fields = append(fields, FormField{Type: Field_Label, Label: strings.TrimRight(line, " ")})
+	}
+	fields = append(fields, FormField{Type: Field_Label}) // a blank line before the input and buttons
+	height := len(fields) + 3                             // buttons and outline
+	if input != nil {
+		fields = append(fields, *input)
+		height += 3
+	}
+	for _, b := range buttons {
+		fields = append(fields, FormField{Type: Field_Button, Name: strings.ToLower(b), Label: b})
+	}
+	tile, err := tTerm.addFloat(title, "", DoubleBox, floatState{center: true, width: width, height: height}, TileType_Form)
+	if err != nil {
+		return nil, err
+	}
+	tile.lock.Lock()
+	tile.form.dialog = true // KeyEscape cancels and KeyEnter in the text field presses OK
+	tile.lock.Unlock()
+	tile.SetFields(fields)
+	tile.SetSubmitCallback(func(button string, values map[string]string) bool {
+		tTerm.DeleteTile(tile)
+		return done(button, values)
+	})
+	tile.SetModal(true)
+	return tile, nil
+}
@@ -0,0 +1,82 @@
+// Example of a config dialog using a TileType_Form tile
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/exyzzy/termfun"
+	"golang.org/x/term"
+)
+
+func main() {
+	// put the terminal in raw mode and save state
+	in := os.Stdin
+	oldState, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		panic(err)
+	}
+	defer term.Restore(int(in.Fd()), oldState)
+
+	fmt.Print(termfun.ED(termfun.EraseAll), "\r\n")
+
+	tTerm := termfun.NewTileTerm(in, os.Stdout)
+
+	// the form is the root, results go to a tile on the right
+	form, err := tTerm.AddTile(" Config ", "", termfun.DoubleBox, 1.0, termfun.Loc_Top, nil, termfun.TileType_Form)
+	if err != nil {
+		panic(err)
+	}
+	out, err := tTerm.AddTile(" Values ", "", termfun.SingleBox, 0.4, termfun.Loc_Right, form, termfun.TileType_ScrollDown)
+	if err != nil {
+		panic(err)
+	}
+
+	form.SetFields([]termfun.FormField{
+		{Type: termfun.Field_Text, Name: "user", Label: "User", Validate: termfun.Required},
+		{Type: termfun.Field_Password, Name: "password", Label: "Password", Validate: termfun.Required},
+		{Type: termfun.Field_Text, Name: "port", Label: "Port", Value: "8080", Validate: port},
+		{Type: termfun.Field_Checkbox, Name: "tls", Label: "Use TLS"},
+		{Type: termfun.Field_Radio, Name: "log", Label: "Log level", Options: []string{"debug", "info", "error"}, Value: "info"},
+		{Type: termfun.Field_Dropdown, Name: "region", Label: "Region", Options: []string{"us-east", "us-west", "eu", "asia"}, Value: "us-east"},
+		{Type: termfun.Field_Button, Name: "save", Label: "Save"},
+		{Type: termfun.Field_Button, Name: "quit", Label: "Quit"},
+	})
+	form.SetSubmitCallback(func(button string, values map[string]string) bool {
+		if button == "quit" {
+			return true
+		}
+		var keys []string
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out.Println(k + ": " + values[k])
+		}
+		out.Println()
+		return false
+	})
+
+	out.Println("Tab, BackTab to move between fields")
+	out.Println("Space or Enter to press a button or open a dropdown")
+	out.Println()
+
+	tTerm.Render()
+	err = tTerm.Start()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// port validates a port number
+func port(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 65535 {
+		return errors.New("not a port number")
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Example of a hex viewer using a TileType_Hex tile, go run examples/hex.go [file]
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/exyzzy/termfun"
+	"golang.org/x/term"
+)
+
+func main() {
+	// view the file named on the command line, or this program itself
+	name := os.Args[0]
+	if len(os.Args) > 1 {
+		name = os.Args[1]
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		panic(err)
+	}
+
+	// put the terminal in raw mode and save state
+	in := os.Stdin
+	oldState, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		panic(err)
+	}
+	defer term.Restore(int(in.Fd()), oldState)
+
+	fmt.Print(termfun.ED(termfun.EraseAll), "\r\n")
+
+	tTerm := termfun.NewTileTerm(in, os.Stdout)
+
+	// the hex dump is the root, help goes to a tile below
+	hex, err := tTerm.AddTile(" "+info.Name()+" ", "", termfun.DoubleBox, 1.0, termfun.Loc_Top, nil, termfun.TileType_Hex)
+	if err != nil {
+		panic(err)
+	}
+	help, err := tTerm.AddTile(" Help ", "", termfun.SingleBox, 0.2, termfun.Loc_Bottom, hex, termfun.TileType_ScrollDown)
+	if err != nil {
+		panic(err)
+	}
+
+	// the file is read as the rows are shown, never loaded whole
+	hex.SetReaderAt(f, info.Size())
+
+	help.Println("Arrows, PgUp, PgDn, Home, End to move, g to go to an offset, like 0x1f0")
+	help.Println("/ to find hex bytes like 7f 45 4c 46, or text, n, N for the next, previous match")
+	help.Println("Space to start and stop selecting, Escape to clear, Ctrl-Q to quit")
+
+	tTerm.Render()
+	err = tTerm.Start()
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,66 @@
+// Example of a shell and tabs side by side using TileType_PTY and TileType_Tabs tiles
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/exyzzy/termfun"
+	"golang.org/x/term"
+)
+
+func main() {
+	// put the terminal in raw mode and save state
+	in := os.Stdin
+	oldState, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		panic(err)
+	}
+	defer term.Restore(int(in.Fd()), oldState)
+
+	fmt.Print(termfun.ED(termfun.EraseAll), "\r\n")
+
+	tTerm := termfun.NewTileTerm(in, os.Stdout)
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	// a shell on the left, tabs on the right with top and notes
+	left, err := tTerm.AddTile(" Shell ", "", termfun.DoubleBox, 1.0, termfun.Loc_Top, nil, termfun.TileType_PTY)
+	if err != nil {
+		panic(err)
+	}
+	right, err := tTerm.AddTile("", "", termfun.SingleBox, 0.5, termfun.Loc_Right, left, termfun.TileType_Tabs)
+	if err != nil {
+		panic(err)
+	}
+	top, err := right.AddTab("Top", "", termfun.TileType_PTY)
+	if err != nil {
+		panic(err)
+	}
+	notes, err := right.AddTab("Notes", "", termfun.TileType_ScrollDown)
+	if err != nil {
+		panic(err)
+	}
+	notes.Println("Ctrl-N, Ctrl-P or click a tab to switch tabs")
+	notes.Println("Click a tile to focus it")
+	tTerm.SetMouse(true)
+
+	// the tiles are laid out on the first Render, start the programs after it so the pty gets the tile size
+	tTerm.Render()
+	if err := left.StartPTY(exec.Command(shell)); err != nil {
+		panic(err)
+	}
+	if err := top.StartPTY(exec.Command("top")); err != nil {
+		panic(err)
+	}
+
+	// Ctrl-T to switch tiles, Ctrl-U to zoom, Ctrl-X for copy mode, Ctrl-Q to quit
+	err = tTerm.Start()
+	if err != nil {
+		panic(err)
+	}
+}
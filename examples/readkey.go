@@ -46,11 +46,19 @@ func main() {
 }
 
 var keyMap = map[rune]string{
-	termfun.KeyUnknown: "Unknown",
-	termfun.KeyUp:      "Up",
-	termfun.KeyDown:    "Down",
-	termfun.KeyLeft:    "Left",
-	termfun.KeyRight:   "Right",
-	termfun.KeyBackTab: "BackTab",
-	termfun.KeyDel:     "Delete",
+	termfun.KeyUnknown:   "Unknown",
+	termfun.KeyUp:        "Up",
+	termfun.KeyDown:      "Down",
+	termfun.KeyLeft:      "Left",
+	termfun.KeyRight:     "Right",
+	termfun.KeyBackTab:   "BackTab",
+	termfun.KeyDel:       "Delete",
+	termfun.KeyHome:      "Home",
+	termfun.KeyEnd:       "End",
+	termfun.KeyPgUp:      "PgUp",
+	termfun.KeyPgDn:      "PgDn",
+	termfun.KeyInsert:    "Insert",
+	termfun.KeyWordLeft:  "WordLeft",
+	termfun.KeyWordRight: "WordRight",
+	termfun.KeyYankPop:   "YankPop",
 }
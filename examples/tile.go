@@ -2,14 +2,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/exyzzy/termfun"
+	"github.com/exyzzy/termfun/format"
 	"github.com/exyzzy/termfun/lorem"
 	"golang.org/x/term"
 )
@@ -35,8 +39,15 @@ func main() {
 	}
 
 	// create a lineCallback for the root
-	tt := &TermType{tile: t0}
+	tt := &TermType{tTerm: tTerm, tile: t0}
 	t0.SetLineCallback(tt.lineHandler)
+	t0.SetCompletionCallback(termfun.FileCompleter)
+	t0.SetHistory(termfun.History{File: filepath.Join(os.TempDir(), "termfun_tile_history"), IgnoreDups: true, IgnoreSpace: true})
+
+	// copy mode yanks to the terminal clipboard and reports to the root
+	tTerm.SetYankCallback(func(text string) {
+		t0.Printf("yanked %d bytes\n", len(text))
+	}, true)
 
 	// print to the root text buffer
 	t0.Println("Hello, t0: root, TileType_ScrollUp, bash lineHandler")
@@ -47,6 +58,17 @@ func main() {
 		panic(err)
 	}
 
+	// a status bar pinned to the bottom, its clock ticks every second
+	bar, err := tTerm.AddStatusBar(termfun.Loc_Bottom)
+	if err != nil {
+		panic(err)
+	}
+	bar.SetSegments(
+		[]termfun.SegmentFunc{tTerm.FocusSegment(), tTerm.ModeSegment()},
+		[]termfun.SegmentFunc{func() string { return "termfun demo" }},
+		[]termfun.SegmentFunc{termfun.Clock("15:04:05")})
+	bar.SetTick(time.Second)
+
 	// render what we have so far
 	tTerm.Render()
 
@@ -65,16 +87,23 @@ func main() {
 
 
 type TermType struct {
-	tile *termfun.Tile
+	tTerm *termfun.TileTerm
+	tile  *termfun.Tile
 }
 
 // lineHandler is the root LineCallback
 func (tt *TermType) lineHandler(line string) bool {
 	tt.tile.Printf("%s%s\n", tt.tile.Cursor(), line)
-	out, _ := exec.Command("bash", "-c", line).CombinedOutput()
-	tt.tile.Print(string(out))
-	if strings.Contains(line, "quit") { //example of how to exit TileTerm from LineCallback
-		return true
+	if strings.Contains(line, "quit") { //example of how to exit TileTerm from a modal dialog callback
+		tt.tTerm.Confirm(" Quit ", "Quit the demo?", func(ok bool) bool {
+			return ok
+		})
+		return false
+	}
+	// output streams in while the UI runs, input lines go to the command, Ctrl-C interrupts it
+	err := tt.tile.Exec(exec.Command("bash", "-c", line))
+	if err != nil {
+		tt.tile.Println(err)
 	}
 	return false
 }
@@ -83,7 +112,8 @@ func (tt *TermType) lineHandler(line string) bool {
 func life(tTerm *termfun.TileTerm) {
 	time.Sleep(time.Second * 2)
 
-	t5, err := tTerm.AddTile(" Life ", "", termfun.SingleBox, 0.5, termfun.Loc_Top, tTerm.TileByIndex(3), termfun.TileType_ScrollDownClipRaw)
+	// float over the tiles, centered
+	t5, err := tTerm.AddCenterTile(" Life ", "", termfun.SingleBox, 40, 16, termfun.TileType_Canvas)
 	if err != nil {
 		return
 	}
@@ -93,18 +123,17 @@ func life(tTerm *termfun.TileTerm) {
 		panic(err)
 	}
 
-	tTerm.Render() //render to set t5 tile size
+	tTerm.Render() //render to lay out t5, which sizes its canvas
 
-	//size it to the tile, assume aspect render
-	c := termfun.NewCanvas(t5.Width(), t5.Height()*2)
-	c.PlotWrap()
-	Randomize(c)
-	// fmt.Print(termfun.ED(termfun.EraseAll), "\r\n")
+	t5.DrawCanvas(func(c *termfun.Canvas) {
+		c.PlotWrap()
+		Randomize(c)
+	})
 
 	for i := 0; i < 300; i++ {
-		t5.ResetBuffer()
-		t5.Print(c.StringAspect())
-		c = NextFrame(c)
+		t5.DrawCanvas(func(c *termfun.Canvas) { // not resized while drawing
+			c.Copy(NextFrame(c))
+		})
 		time.Sleep(time.Second / time.Duration(lt.frameRate))
 		tTerm.Render()
 	}
@@ -136,7 +165,7 @@ func (lt *LifeType) keyHandler(k rune) bool {
 	return false
 }
 
-// AddTilesAndText adds 3 new tiles and some random text, and instructions
+// AddTilesAndText adds 4 new tiles and some random text, and instructions
 func AddTilesAndText(tTerm *termfun.TileTerm, t0 *termfun.Tile) error {
 
 	// make some tiles in the tTerm
@@ -153,6 +182,31 @@ func AddTilesAndText(tTerm *termfun.TileTerm, t0 *termfun.Tile) error {
 		return err
 	}
 
+	t5, err := tTerm.AddTile(" Menu ", "", termfun.SingleBox, 0.4, termfun.Loc_Bottom, t3, termfun.TileType_List)
+	if err != nil {
+		return err
+	}
+
+	// pick fruit from the menu, reporting to the root
+	var items []termfun.ListItem
+	for _, f := range []string{"Apple", "Apricot", "Banana", "Blueberry", "Cherry", "Grape", "Lemon", "Mango", "Orange", "Peach", "Pear", "Plum"} {
+		items = append(items, termfun.ListItem{Label: f})
+	}
+	t5.SetItems(items)
+	t5.SetMultiSelect(true)
+	t5.SetSelectCallback(func(selected []int) bool {
+		for _, i := range selected {
+			t0.Println("picked", items[i].Label)
+		}
+		return false
+	})
+
+	// flow t1 into newspaper columns
+	t1.SetColumns(2, 3)
+
+	// keep list structure when t3 wraps bullets
+	t3.SetIndent(format.Indent{Hanging: true, Prefixes: format.QuotePrefixes})
+
 	// print some text to the tile buffers
 	t1.Println("Hello, t1: TileType_ScrollDown")
 	t2.Println("Hello, t2: TileType_ScrollDownClip")
@@ -164,6 +218,8 @@ func AddTilesAndText(tTerm *termfun.TileTerm, t0 *termfun.Tile) error {
 	t2.Println("\tAnotherVeryveryveryLongveryveryveryveryEversolongveryveryveryveryveryveryveryveryveryVeryVeryveryveryveryveryveryveryvery, very, very, very, very, very, very, very, very, very, very...long line")
 	t3.Println("Another ever so very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very, very ...long line")
 	t1.Println(lorem.GenerateLorem(500))
+	t3.Println("\t- " + lorem.GenerateLorem(20))
+	t3.Println("> " + lorem.GenerateLorem(20))
 	t3.Println(lorem.GenerateLorem(100))
 
 	return nil
@@ -176,6 +232,7 @@ func instructions(t *termfun.Tile) {
 	t.Println("\t- Ctrl-T to cycle focus to next window")
 	t.Println("\t- Ctrl-U to make this window big (toggle)")
 	t.Println("\t- Ctrl-Q to quit (exit demo)")
+	t.Println("\t- Ctrl-X for copy mode, arrows to move, v/V/Ctrl-V to select, y to yank, q to leave")
 	t.Println("For Life:")
 	t.Println("\t- Up Arrow to increase frame rate")
 	t.Println("\t- Down Arrow to decrease frame rate")
@@ -183,31 +240,54 @@ func instructions(t *termfun.Tile) {
 	t.Println("For TileType_ScrollDown:")
 	t.Println("\t- Up Arrow to scroll up one line")
 	t.Println("\t- Down Arrow to scroll down one line")
+	t.Println("For TileType_ScrollDown with columns (Text1):")
+	t.Println("\t- Up Arrow to scroll up one page")
+	t.Println("\t- Down Arrow to scroll down one page")
 	t.Println("For TileType_ScrollDownClip:")
 	t.Println("\t- Up Arrow to scroll up one line")
 	t.Println("\t- Down Arrow to scroll down one line")
 	t.Println("\t- Left Arrow to scroll left one column")
 	t.Println("\t- Right Arrow to scroll right one column")
+	t.Println("For TileType_ScrollDown and TileType_ScrollDownClip:")
+	t.Println("\t- PgUp/PgDn to scroll one page, u/d half a page")
+	t.Println("\t- Home/End to scroll to top/bottom")
+	t.Println("\t- / to search, Enter to find, n/N for next/previous match")
+	t.Println("For Text4 in follow mode:")
+	t.Println("\t- Up Arrow to pause following")
+	t.Println("\t- End to resume following")
+	t.Println("For TileType_List (Menu):")
+	t.Println("\t- Up/Down Arrow to move, type to filter")
+	t.Println("\t- Space to check, Enter to pick")
 	t.Println("For TileType_ScrollUp:")
-	t.Println("\t- Enter to send line to bash and print result")
+	t.Println("\t- Enter to run line in bash, the output streams in")
+	t.Println("\t- While it runs, Enter sends a line to it, Ctrl-C interrupts it")
 	t.Println("\t- Type 'ls<enter>' for instance")
+	t.Println("\t- Type 'quit<enter>' to quit from a confirm dialog, Escape to cancel")
+	t.Println("\t- Tab to complete a file path")
+	t.Println("\t- Ctrl-R to search the history")
 }
 
 // counter starts the counter tile in the go routine
-// print line to tile every second and render
+// write a line to a pipe every second, the tile follows the pipe and renders as lines arrive
 func counter(tTerm *termfun.TileTerm) {
 	// new tile pops after 5 seconds
 	time.Sleep(time.Second * 5)
-	t4, err := tTerm.AddTile(" Text4: Counting tile, a longer title ", "", termfun.SingleBox, 0.3, termfun.Loc_Top, tTerm.TileByIndex(0), termfun.TileType_ScrollDown)
+	// float over the top left of the tiles
+	t4, err := tTerm.AddFloatTile(" Text4: Counting tile, a longer title ", "", termfun.SingleBox, termfun.Rect{Min: termfun.Point{X: 3, Y: 2}, Max: termfun.Point{X: 44, Y: 8}}, termfun.TileType_ScrollDown)
 	if err != nil {
 		return
 	}
+	t4.SetScrollback(10, 0) // only keep the last 10 counts
+	t4.SetFollow(true)      // show the latest count
 	tTerm.Render()
+	r, w := io.Pipe()
+	done := t4.Follow(context.Background(), r)
 	for i := 0; i <= 20; i++ {
-		t4.Println(i)
-		tTerm.Render()
+		fmt.Fprintln(w, i)
 		time.Sleep(time.Second * 1)
 	}
+	w.Close()
+	<-done // EOF
 	tTerm.DeleteTile(t4)
 	tTerm.Render()
 }
@@ -0,0 +1,64 @@
+// Example of a file browser using a TileType_Tree tile
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/exyzzy/termfun"
+	"golang.org/x/term"
+)
+
+func main() {
+	// put the terminal in raw mode and save state
+	in := os.Stdin
+	oldState, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		panic(err)
+	}
+	defer term.Restore(int(in.Fd()), oldState)
+
+	fmt.Print(termfun.ED(termfun.EraseAll), "\r\n")
+
+	tTerm := termfun.NewTileTerm(in, os.Stdout)
+
+	// the tree is the root, the selected file is shown on the right
+	tree, err := tTerm.AddTile(" Files ", "", termfun.DoubleBox, 1.0, termfun.Loc_Top, nil, termfun.TileType_Tree)
+	if err != nil {
+		panic(err)
+	}
+	view, err := tTerm.AddTile(" View ", "", termfun.SingleBox, 0.65, termfun.Loc_Right, tree, termfun.TileType_ScrollDownClip)
+	if err != nil {
+		panic(err)
+	}
+
+	fsys := os.DirFS(".")
+	tree.SetRoot(termfun.NewFSTree(fsys, "."))
+	tree.SetTreeCallback(func(node termfun.TreeNode, path []termfun.TreeNode) bool {
+		fsNode := node.(*termfun.FSNode)
+		view.ResetBuffer()
+		view.SetStart(termfun.Point{})
+		if fsNode.Dir {
+			view.Println(fsNode.Path)
+			return false
+		}
+		data, err := fs.ReadFile(fsys, fsNode.Path)
+		if err != nil {
+			view.Println(err)
+			return false
+		}
+		view.Print(strings.ReplaceAll(string(data), "\r\n", "\n"))
+		return false
+	})
+
+	view.Println("Up, Down to move, Right, Left to expand, collapse")
+	view.Println("Enter to view a file, Ctrl-T to switch tiles, Ctrl-Q to quit")
+
+	tTerm.Render()
+	err = tTerm.Start()
+	if err != nil {
+		panic(err)
+	}
+}
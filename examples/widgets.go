@@ -0,0 +1,65 @@
+// Example of a dashboard using a TileType_Widgets tile
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/exyzzy/termfun"
+	"golang.org/x/term"
+)
+
+func main() {
+	// put the terminal in raw mode and save state
+	in := os.Stdin
+	oldState, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		panic(err)
+	}
+	defer term.Restore(int(in.Fd()), oldState)
+
+	fmt.Print(termfun.ED(termfun.EraseAll), "\r\n")
+
+	tTerm := termfun.NewTileTerm(in, os.Stdout)
+	dash, err := tTerm.AddTile(" Dashboard (Ctrl-Q to quit) ", "", termfun.DoubleBox, 1.0, termfun.Loc_Top, nil, termfun.TileType_Widgets)
+	if err != nil {
+		panic(err)
+	}
+
+	// the widgets are updated in place by a go routine
+	spin := termfun.NewSpinner("copying files")
+	job := termfun.NewProgressBar("copy")
+	cpu := termfun.NewGauge("cpu", 100, "%")
+	cpu.Warn, cpu.Crit = 60, 85
+	rate := termfun.NewSparkline("rate", 0, "%.0f/s")
+	disk := termfun.NewVProgressBar("disk", 4)
+	for _, w := range []termfun.Widget{spin, job, cpu, rate, disk} {
+		dash.AddWidget(w)
+	}
+
+	go func() {
+		for i := 0; ; i++ {
+			spin.Tick()
+			if i <= 200 {
+				job.Set(float64(i) / 200)
+			}
+			if i == 200 {
+				spin.Stop("copy done")
+			}
+			if i%5 == 0 {
+				cpu.Set(float64(rand.Intn(100)))
+				rate.Add(float64(50 + rand.Intn(50)))
+				disk.Set(float64(i%400) / 400)
+			}
+			tTerm.Render()
+			time.Sleep(time.Second / 10)
+		}
+	}()
+
+	err = tTerm.Start()
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,287 @@
+package termfun
+
+// find.go supports searching within TileType_ScrollDown and TileType_ScrollDownClip tiles.
+// The find key, '/' by default, starts typing a query that is used as a regexp,
+// or as literal text if it is not a valid regexp. Enter jumps to the first match
+// at or below the top of the view, n and N move to the next and previous match,
+// and Escape or Ctrl-G ends the search. All visible matches are highlighted.
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// defaultFindKey starts a search in scrollable tiles, see Tile.SetFindKey
+const defaultFindKey = '/'
+
+// findMatch is a match in the rendered lines, in display columns
+type findMatch struct {
+	line, col, n int
+}
+
+// findState holds the search state of a tile
+type findState struct {
+	typing  bool           // typing the query
+	query   string         // query text
+	re      *regexp.Regexp // compiled query, or nil if not searching
+	matches []findMatch    // matches from the last render
+	index   int            // current match, or -1
+	jump    bool           // jump to the first match from the top of the view on the next render
+}
+
+// SetFindKey sets the key that starts a search in scrollable tiles, or 0 to disable search
+func (t *Tile) SetFindKey(r rune) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.findKey = r
+}
+
+// findKeyPress handles the search keys, returning true if the key was used
+func (t *Tile) findKeyPress(r rune) bool {
+	f := &t.find
+	if f.typing {
+		switch r {
+		case KeyEnter:
+			f.typing = false
+			if f.query == "" {
+				f.re = nil
+				break
+			}
+			re, err := regexp.Compile(f.query)
+			if err != nil {
+				re = regexp.MustCompile(regexp.QuoteMeta(f.query))
+			}
+			f.re = re
+			f.jump = true
+		case KeyEscape, CtrlG:
+			*f = findState{index: -1}
+		case KeyBackspace, CtrlH:
+			if f.query != "" {
+				_, size := utf8.DecodeLastRuneInString(f.query)
+				f.query = f.query[:len(f.query)-size]
+			}
+		default:
+			if !unicode.IsPrint(r) {
+				return true // ignore other special keys while typing
+			}
+			f.query += string(r)
+		}
+		t.setDirty()
+		return true
+	}
+	switch {
+	case r == t.findKey && r != 0:
+		*f = findState{typing: true, index: -1}
+	case f.re == nil:
+		return false
+	case r == 'n':
+		t.findMove(1)
+	case r == 'N':
+		t.findMove(-1)
+	case r == KeyEscape || r == CtrlG:
+		*f = findState{index: -1}
+	default:
+		return false
+	}
+	t.setDirty()
+	return true
+}
+
+// findMove moves to the next (1) or previous (-1) match, wrapping around
+func (t *Tile) findMove(dir int) {
+	f := &t.find
+	n := len(f.matches)
+	if n == 0 {
+		return
+	}
+	if f.index < 0 {
+		f.index = 0
+		if dir < 0 {
+			f.index = n - 1
+		}
+	} else {
+		f.index = (f.index + dir + n) % n
+	}
+	t.findShow(f.matches[f.index])
+}
+
+// findShow scrolls so a match is visible, for TileType_ScrollDownClip also horizontally
+func (t *Tile) findShow(m findMatch) {
+	t.following = false // followStart resumes if the match is at the bottom
+	if m.line < t.start.Y || m.line >= t.start.Y+t.Height() {
+		t.start.Y = m.line
+		if t.columns > 1 { // keep paging by screenful
+			t.start.Y -= m.line % t.Height()
+		}
+	}
+	if t.handler.TileType != TileType_ScrollDownClip {
+		return
+	}
+	if w := t.Width(); m.col < t.start.X || m.col+m.n > t.start.X+w {
+		t.start.X = m.col - (w-m.n)/2
+		if t.start.X < 0 || m.col+m.n <= w {
+			t.start.X = 0
+		}
+	}
+}
+
+// findUpdate finds the matches in lines, which are all the text lines before clipping,
+// and does any pending jump to the first match at or below the top of the view
+// Newspaper columns are searched one at a time, so a match never spans the gutter
+func (t *Tile) findUpdate(lines []string) {
+	f := &t.find
+	f.matches = f.matches[:0]
+	if f.re == nil {
+		return
+	}
+	columns, colWidth, step := t.findColumns()
+	for i, line := range lines {
+		for c := 0; c < columns; c++ {
+			text, x0 := line, 0
+			if columns > 1 {
+				x0 = c * step
+				text = columnText(line, x0, x0+colWidth)
+			}
+			for _, loc := range f.re.FindAllStringIndex(text, -1) {
+				if loc[1] == loc[0] {
+					continue // empty matches can't be seen
+				}
+				col := x0 + format.StringWidth(text[:loc[0]])
+				f.matches = append(f.matches, findMatch{line: i, col: col, n: format.StringWidth(text[loc[0]:loc[1]])})
+			}
+		}
+	}
+	if f.index >= len(f.matches) {
+		f.index = len(f.matches) - 1
+	}
+	if f.jump {
+		f.jump = false
+		f.index = -1
+		for i, m := range f.matches {
+			if m.line >= t.start.Y {
+				f.index = i
+				break
+			}
+		}
+		if f.index < 0 && len(f.matches) > 0 {
+			f.index = 0 // wrap around
+		}
+		if f.index >= 0 {
+			t.findShow(f.matches[f.index])
+		}
+	}
+}
+
+// findColumns returns the newspaper columns of the rendered lines, the column width and the columns
+// from one column to the next, as FormatTextColumns lays them out
+func (t *Tile) findColumns() (int, int, int) {
+	if t.columns <= 1 || t.handler.TileType != TileType_ScrollDown {
+		return 1, 0, 0
+	}
+	colWidth := (t.Width() - t.gutter*(t.columns-1)) / t.columns
+	if colWidth < 1 {
+		return 1, 0, 0
+	}
+	return t.columns, colWidth, colWidth + t.gutter
+}
+
+// columnText returns the graphemes of line from display column x0 up to x1
+func columnText(line string, x0, x1 int) string {
+	var b strings.Builder
+	x := 0
+	for i := 0; i < len(line) && x < x1; {
+		j := format.NextGrapheme(line, i)
+		if x >= x0 {
+			b.WriteString(line[i:j])
+		}
+		x += format.GraphemeWidth(line[i:j])
+		i = j
+	}
+	return b.String()
+}
+
+// findHighlight highlights the visible matches in lines, which are clipped starting at display column col
+// Lines may hold SGR styles, such as from a Highlighter, the match style is drawn over them
+func (t *Tile) findHighlight(lines []string, col int) {
+	f := &t.find
+	for i := 0; i < len(f.matches); {
+		y := f.matches[i].line
+		if y < t.start.Y || y >= t.start.Y+t.Height() || y >= len(lines) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(f.matches) && f.matches[j].line == y {
+			j++
+		}
+		lines[y] = t.findLine(lines[y], f.matches[i:j], i, col)
+		i = j
+	}
+}
+
+// findLine returns line with matches styled, first is the index of matches[0] in all the matches
+// and col the display column of the start of line. The styles of line are restored after each match.
+func (t *Tile) findLine(line string, matches []findMatch, first, col int) string {
+	var b strings.Builder
+	var style string // SGR styles of the line in effect
+	var hl string    // SGR of the match being drawn, or ""
+	x, m := 0, 0     // display column, next match
+	for i := 0; i < len(line); {
+		j := format.NextGrapheme(line, i)
+		g := line[i:j]
+		i = j
+		if strings.HasPrefix(g, CSI) {
+			b.WriteString(g)
+			if strings.HasSuffix(g, "m") {
+				if g == SGR(SGR_Off) {
+					style = ""
+				} else {
+					style += g
+				}
+				b.WriteString(hl) // keep the match style over the line styles
+			}
+			continue
+		}
+		for m < len(matches) && matches[m].col-col+matches[m].n <= x {
+			if hl != "" {
+				b.WriteString(SGR(SGR_Off) + style)
+				hl = ""
+			}
+			m++
+		}
+		if hl == "" && m < len(matches) && x >= matches[m].col-col {
+			hl = SGR(SGR_Negative)
+			if first+m == t.find.index {
+				hl = SGR(SGR_Negative, SGR_Underline)
+			}
+			b.WriteString(hl)
+		}
+		b.WriteString(g)
+		x += format.GraphemeWidth(g)
+	}
+	if hl != "" {
+		b.WriteString(SGR(SGR_Off) + style)
+	}
+	return b.String()
+}
+
+// findStatus returns the search status for the tile border, or ""
+func (t *Tile) findStatus() string {
+	f := &t.find
+	switch {
+	case f.typing:
+		return "/" + f.query + "_"
+	case f.re == nil:
+		return ""
+	case len(f.matches) == 0:
+		return fmt.Sprintf("/%s no match", f.query)
+	case f.index < 0:
+		return fmt.Sprintf("/%s %d matches", f.query, len(f.matches))
+	}
+	return fmt.Sprintf("/%s %d/%d", f.query, f.index+1, len(f.matches))
+}
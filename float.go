@@ -0,0 +1,161 @@
+package termfun
+
+// float.go supports floating tiles, drawn over the tiled layout rather than carved out of a parent.
+// Floats are placed at an absolute rect or centered, stack in a z-order, and when one is deleted
+// the session renders again to restore what was under it. A modal float keeps the focus until it is deleted.
+
+import "errors"
+
+// floatState holds the placement of a floating tile
+type floatState struct {
+	rect      Rect  // outer bounds including the outline, unless centered
+	center    bool  // if true, center width x height in the terminal
+	width     int   // outer width when centered
+	height    int   // outer height when centered
+	modal     bool  // keeps the focus until deleted
+	prevFocus *Tile // focus tile to restore when deleted
+}
+
+// AddFloatTile adds a floating tile over the tiled layout at rect, the outer bounds including the outline,
+// clipped to the terminal. It is on top of the z-order, see TileTerm.Raise, and remove it with DeleteTile.
+func (tTerm *TileTerm) AddFloatTile(name string, cursor string, outline [6]int, rect Rect, handler TileType) (*Tile, error) {
+	return tTerm.addFloat(name, cursor, outline, floatState{rect: rect}, handler)
+}
+
+// AddCenterTile adds a floating tile of width x height, including the outline, centered in the terminal
+// and kept centered when the terminal is resized, see AddFloatTile
+func (tTerm *TileTerm) AddCenterTile(name string, cursor string, outline [6]int, width, height int, handler TileType) (*Tile, error) {
+	return tTerm.addFloat(name, cursor, outline, floatState{center: true, width: width, height: height}, handler)
+}
+
+// addFloat adds a floating tile on top of the z-order
+func (tTerm *TileTerm) addFloat(name string, cursor string, outline [6]int, float floatState, handler TileType) (*Tile, error) {
+	th, err := getTileHandler(handler)
+	if err != nil {
+		return nil, err
+	}
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	if len(tTerm.tiles) == 0 {
+		return nil, errors.New("no root tile")
+	}
+	tile := tTerm.newTile(name, cursor, outline, th)
+	tile.float = &float
+	tTerm.floats = append(tTerm.floats, tile)
+	tTerm.dirty = true
+	return tile, nil
+}
+
+// SetModal makes a floating tile modal, it takes the focus and keeps it until it is deleted,
+// then the focus goes back to the tile that had it
+func (t *Tile) SetModal(modal bool) error {
+	if t.float == nil || t.term == nil {
+		return errors.New("tile is not floating")
+	}
+	tTerm := t.term
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	t.float.modal = modal
+	if modal && tTerm.focus != t {
+		t.float.prevFocus = tTerm.focus
+		tTerm.focus = t
+	}
+	return nil
+}
+
+// Floating returns true for a floating tile, see AddFloatTile
+func (t *Tile) Floating() bool {
+	return t.float != nil
+}
+
+// Raise moves a floating tile to the top of the z-order
+func (tTerm *TileTerm) Raise(tile *Tile) {
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	for i, f := range tTerm.floats {
+		if f == tile {
+			tTerm.floats = append(append(tTerm.floats[:i:i], tTerm.floats[i+1:]...), tile)
+			tTerm.dirty = true
+			return
+		}
+	}
+}
+
+// modal returns the top modal floating tile, or nil
+func (tTerm *TileTerm) modal() *Tile {
+	for i := len(tTerm.floats) - 1; i >= 0; i-- {
+		if tTerm.floats[i].float.modal {
+			return tTerm.floats[i]
+		}
+	}
+	return nil
+}
+
+// deleteFloat removes a floating tile, restoring the focus it took, and returns true if it was one
+func (tTerm *TileTerm) deleteFloat(tile *Tile) bool {
+	for i, f := range tTerm.floats {
+		if f != tile {
+			continue
+		}
+		tTerm.floats = append(tTerm.floats[:i:i], tTerm.floats[i+1:]...)
+		if tTerm.focus == tile {
+			tTerm.focus = nil
+			if prev := tile.float.prevFocus; prev != nil && tTerm.hasTile(prev) {
+				tTerm.focus = prev
+			} else if len(tTerm.tiles) > 0 {
+				tTerm.focus = tTerm.tiles[0]
+			}
+		}
+		tTerm.dirty = true // render all to restore what was under it
+		return true
+	}
+	return false
+}
+
+// hasTile returns true if tile is a current tile or floating tile
+func (tTerm *TileTerm) hasTile(tile *Tile) bool {
+	for _, t := range tTerm.allTiles() {
+		if t == tile {
+			return true
+		}
+	}
+	return false
+}
+
+// allTiles returns the tiles and status bars followed by the floating tiles, bottom to top
+func (tTerm *TileTerm) allTiles() []*Tile {
+	all := append(tTerm.tiles[:len(tTerm.tiles):len(tTerm.tiles)], tTerm.bars...)
+	return append(all, tTerm.floats...)
+}
+
+// floatBounds returns the bounds of a floating tile, inside the outline, in a terminal of width x height
+func (t *Tile) floatBounds(width, height int) Rect {
+	r := t.float.rect
+	if t.float.center {
+		w, h := t.float.width, t.float.height
+		if w > width {
+			w = width
+		}
+		if h > height {
+			h = height
+		}
+		r.Min = Point{X: (width-w)/2 + 1, Y: (height-h)/2 + 1}
+		r.Max = Point{X: r.Min.X + w - 1, Y: r.Min.Y + h - 1}
+	}
+	if r.Min.X < 1 {
+		r.Min.X = 1
+	}
+	if r.Min.Y < 1 {
+		r.Min.Y = 1
+	}
+	if r.Max.X > width {
+		r.Max.X = width
+	}
+	if r.Max.Y > height {
+		r.Max.Y = height
+	}
+	if t.outline != nil {
+		r = DecRect(r)
+	}
+	return r
+}
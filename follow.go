@@ -0,0 +1,219 @@
+package termfun
+
+// follow.go supports follow mode for TileType_ScrollDown and TileType_ScrollDownClip tiles,
+// where the view sticks to the bottom as new text arrives, like tail -f.
+// Scrolling up pauses following, and KeyEnd or scrolling back down to the bottom resumes it.
+// Tile.Follow and Tile.FollowFile feed a tile from a reader or a growing file in the background.
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// followPoll is how often FollowFile checks for new data, truncation and rotation at the end of the file
+const followPoll = time.Second / 4
+
+// Follow reads r in the background, appending each complete line to the tile buffer and rendering soon after,
+// until EOF, a read error or ctx is done. A last line without "\n" is appended at EOF.
+// The returned channel receives nil at EOF, or the error, then is closed.
+// When ctx is done a blocked read is interrupted if r has SetReadDeadline, like pipes from os.Pipe and net.Conn.
+func (t *Tile) Follow(ctx context.Context, r io.Reader) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- t.followReader(ctx, r)
+		close(done)
+	}()
+	return done
+}
+
+// FollowFile tails the file name in the background like tail -F, appending each complete line to the tile buffer,
+// from the start of the file or only new lines. It reopens the file when it is rotated and
+// starts over when it is truncated, until ctx is done or a read error.
+// The returned channel receives the error, ctx.Err() when ctx is done, then is closed.
+func (t *Tile) FollowFile(ctx context.Context, name string, fromStart bool) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- t.followFile(ctx, name, fromStart)
+		close(done)
+	}()
+	return done
+}
+
+// followReader appends the lines of r until EOF, an error or ctx is done
+func (t *Tile) followReader(ctx context.Context, r io.Reader) error {
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case <-ctx.Done():
+			if d, ok := r.(interface{ SetReadDeadline(time.Time) error }); ok {
+				d.SetReadDeadline(time.Now())
+			}
+		case <-stopped:
+		}
+	}()
+	var partial string
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if n > 0 {
+			partial = t.followAppend(partial + string(buf[:n]))
+		}
+		if err == io.EOF {
+			if partial != "" {
+				t.followAppend(partial + "\n")
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// followFile appends the lines of the file name as it grows, see FollowFile
+func (t *Tile) followFile(ctx context.Context, name string, fromStart bool) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+	if !fromStart {
+		if _, err := f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+	var partial string
+	buf := make([]byte, 32*1024)
+	tick := time.NewTicker(followPoll)
+	defer tick.Stop()
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			partial = t.followAppend(partial + string(buf[:n]))
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if n > 0 && ctx.Err() == nil {
+			continue
+		}
+		// at the end of the file, wait for more, then check for rotation and truncation
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+		}
+		info, err := os.Stat(name)
+		if err != nil { // rotated away and not created again yet
+			continue
+		}
+		cur, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if !os.SameFile(info, cur) { // rotated, finish the old file and switch to the new one
+			next, err := os.Open(name)
+			if err != nil {
+				continue
+			}
+			for {
+				n, err := f.Read(buf)
+				partial = t.followAppend(partial + string(buf[:n]))
+				if n == 0 || err != nil {
+					break
+				}
+			}
+			if partial != "" {
+				t.followAppend(partial + "\n")
+				partial = ""
+			}
+			f.Close()
+			f = next
+			continue
+		}
+		if pos, err := f.Seek(0, io.SeekCurrent); err == nil && info.Size() < pos { // truncated
+			f.Seek(0, io.SeekStart)
+			partial = ""
+		}
+	}
+}
+
+// followAppend appends the complete lines in text to the tile buffer, then renders soon, and returns the rest
+func (t *Tile) followAppend(text string) string {
+	i := strings.LastIndexByte(text, '\n')
+	if i < 0 {
+		return text
+	}
+	t.Write([]byte(text[:i+1]))
+	t.renderLater()
+	return text[i+1:]
+}
+
+// SetFollow turns follow mode on or off for TileType_ScrollDown and TileType_ScrollDownClip
+// The mode is shown in the tile title
+func (t *Tile) SetFollow(follow bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.follow = follow
+	t.following = follow
+	t.dirty = true
+}
+
+// Following returns true if the Tile is in follow mode and not paused
+func (t *Tile) Following() bool {
+	return t.follow && t.following
+}
+
+// followMode returns the follow mode for the title, or "" if follow mode is off
+func (t *Tile) followMode() string {
+	switch {
+	case !t.follow:
+		return ""
+	case t.following:
+		return "follow"
+	}
+	return "paused"
+}
+
+// followLines returns the number of lines to follow, ignoring the blank line after a trailing newline
+func followLines(text string, lines []string) int {
+	n := len(lines)
+	if n > 0 && strings.HasSuffix(text, "\n") {
+		n--
+	}
+	return n
+}
+
+// followStart sets start.Y so the last of n lines is at the bottom of the view
+// when following, or resumes following if the view was scrolled to the bottom
+func (t *Tile) followStart(n int) {
+	if !t.follow {
+		return
+	}
+	bottom := t.scrollBottom()
+	if t.following || t.start.Y >= bottom {
+		t.following = true
+		t.start.Y = bottom
+	}
+}
+
+// followKey pauses following when scrolling up and resumes it on KeyEnd
+func (t *Tile) followKey(r rune) {
+	if !t.follow {
+		return
+	}
+	switch {
+	case r == KeyUp || r == KeyPgUp || r == KeyHome || r == t.halfUp && r != 0:
+		t.following = false
+	case r == KeyEnd:
+		t.following = true
+		t.setDirty()
+	}
+}
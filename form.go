@@ -0,0 +1,481 @@
+package termfun
+
+// form.go implements TileType_Form, a form of labelled fields for small dialogs.
+// Text fields are edited with the line editor, Tab and BackTab move between fields,
+// and a button validates the fields and sends the values to the SubmitCallback.
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// FieldType is the kind of a FormField
+type FieldType int
+
+const (
+	Field_Text     FieldType = iota // single line text input
+	Field_Password                  // text input shown masked
+	Field_Checkbox                  // on or off
+	Field_Radio                     // one of Options, all shown
+	Field_Dropdown                  // one of Options, shown in a list when open
+	Field_Button                    // validates and submits the form
+	Field_Label                     // a line of text that does not take focus
+)
+
+// FormField is a field of a TileType_Form tile
+// Name: key of the field in the form values, or "" to use Label
+// Value: text of a text field, or the chosen option of a radio group or dropdown
+// Checked: state of a checkbox
+// Validate: returns an error if value is not valid, or nil, it is called on submit
+type FormField struct {
+	Type     FieldType
+	Name     string
+	Label    string
+	Value    string
+	Checked  bool
+	Options  []string
+	Validate func(value string) error
+}
+
+// SubmitCallback is called when a button of a TileType_Form tile is pressed and all fields are valid
+// button is the Name, or Label, of the button, values holds each non button field by Name, or Label,
+// checkboxes are "true" or "false". Returning true exits TileTerm.
+type SubmitCallback func(button string, values map[string]string) bool
+
+// formMask is shown for each character of a Field_Password
+const formMask = "*"
+
+// formState holds the state of a TileType_Form tile
+type formState struct {
+	fields   []FormField
+	focus    int            // focus field index
+	open     bool           // the focus dropdown is open
+	option   int            // highlighted option of the open dropdown
+	err      string         // validation error shown in the bottom outline, or ""
+	errField int            // field with the validation error, or -1
+	callback SubmitCallback // called on submit, or nil
+	dialog   bool           // KeyEscape cancels and KeyEnter in a text field presses the first button
+}
+
+// Required is a FormField Validate function for fields that must not be empty
+func Required(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return errors.New("required")
+	}
+	return nil
+}
+
+// SetFields sets the fields of a TileType_Form tile and focuses the first field
+func (t *Tile) SetFields(fields []FormField) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Form {
+		return errors.New("Handler.TileType does not support Fields")
+	}
+	t.form.fields = append([]FormField(nil), fields...)
+	t.form.err, t.form.errField = "", -1
+	t.start.Y = 0
+	t.form.focus = 0
+	t.formFocus(0)
+	t.dirty = true
+	return nil
+}
+
+// SetSubmitCallback sets the Submit Callback function for TileType_Form
+func (t *Tile) SetSubmitCallback(c SubmitCallback) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Form {
+		return errors.New("Handler.TileType does not support Callback")
+	}
+	t.form.callback = c
+	return nil
+}
+
+// Values returns the current values of a TileType_Form tile, as passed to a SubmitCallback
+func (t *Tile) Values() map[string]string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.formValues()
+}
+
+// fieldName returns the Name of a field, or its Label
+func fieldName(f *FormField) string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return f.Label
+}
+
+// formValues returns the values of all non button fields
+func (t *Tile) formValues() map[string]string {
+	values := make(map[string]string)
+	for i := range t.form.fields {
+		f := &t.form.fields[i]
+		switch f.Type {
+		case Field_Button, Field_Label:
+		case Field_Checkbox:
+			if f.Checked {
+				values[fieldName(f)] = "true"
+			} else {
+				values[fieldName(f)] = "false"
+			}
+		default:
+			values[fieldName(f)] = f.Value
+		}
+	}
+	return values
+}
+
+// formValidate validates all fields, focusing the first invalid field, and returns true if all are valid
+func (t *Tile) formValidate() bool {
+	fm := &t.form
+	fm.err, fm.errField = "", -1
+	for i := range fm.fields {
+		f := &fm.fields[i]
+		if f.Validate == nil || f.Type == Field_Button || f.Type == Field_Label {
+			continue
+		}
+		value := f.Value
+		if f.Type == Field_Checkbox {
+			value = t.formValues()[fieldName(f)]
+		}
+		if err := f.Validate(value); err != nil {
+			fm.err, fm.errField = f.Label+": "+err.Error(), i
+			t.formFocus(i)
+			return false
+		}
+	}
+	return true
+}
+
+// formFocus moves the focus to field i, loading a text field into the line editor
+// Labels are skipped, moving on in the direction from the current focus
+func (t *Tile) formFocus(i int) {
+	fm := &t.form
+	fm.open = false
+	n := len(fm.fields)
+	if n == 0 {
+		fm.focus = 0
+		return
+	}
+	dir := 1
+	if i < fm.focus {
+		dir = -1
+	}
+	for j := 0; j < n && fm.fields[(i%n+n)%n].Type == Field_Label; j++ {
+		i += dir
+	}
+	fm.focus = (i%n + n) % n
+	if f := &fm.fields[fm.focus]; f.Type == Field_Text || f.Type == Field_Password {
+		t.setLine(f.Value)
+	}
+}
+
+// optionIndex returns the index of the chosen option of a field, or -1
+func optionIndex(f *FormField) int {
+	for i, o := range f.Options {
+		if o == f.Value {
+			return i
+		}
+	}
+	return -1
+}
+
+// formHeight returns the rows used by a field, buttons share one row
+func (t *Tile) formHeight(i int) int {
+	fm := &t.form
+	f := &fm.fields[i]
+	switch f.Type {
+	case Field_Text, Field_Password:
+		return 3
+	case Field_Dropdown:
+		if fm.open && i == fm.focus {
+			return 3 + len(f.Options)
+		}
+		return 3
+	case Field_Radio:
+		return 1 + len(f.Options)
+	case Field_Button:
+		if i > 0 && fm.fields[i-1].Type == Field_Button {
+			return 0
+		}
+	}
+	return 1
+}
+
+// formLayout returns the rect of each field relative to the top left of the form
+func (t *Tile) formLayout() []Rect {
+	rects := make([]Rect, len(t.form.fields))
+	var x, y int
+	for i := range t.form.fields {
+		f := &t.form.fields[i]
+		h := t.formHeight(i)
+		w := t.Width()
+		if f.Type == Field_Button {
+			w = len([]rune(f.Label)) + 4
+			if h == 0 { // next to the previous button
+				y--
+				h = 1
+			} else {
+				x = 0
+			}
+		} else {
+			x = 0
+		}
+		rects[i] = Rect{Min: Point{X: x, Y: y}, Max: Point{X: x + w - 1, Y: y + h - 1}}
+		x += w + 1
+		y += h
+	}
+	return rects
+}
+
+// formText returns the visible text of a text field clipped to width around the edit position,
+// and the column of the edit position
+func (t *Tile) formText(f *FormField, focus bool, width int) (string, int) {
+	value, pos := f.Value, 0
+	if focus {
+		value, pos = t.line, t.linePos
+	}
+	if f.Type == Field_Password { // one mask per grapheme
+		pos = graphemeCount(value[:pos]) * len(formMask)
+		value = strings.Repeat(formMask, graphemeCount(value))
+	}
+	col := format.StringWidth(value[:pos])
+	skip := col - width + 1 // scroll left so the edit position is visible
+	for skip > 0 && value != "" {
+		n := format.NextGrapheme(value, 0)
+		skip -= format.GraphemeWidth(value[:n])
+		col -= format.GraphemeWidth(value[:n])
+		value = value[n:]
+	}
+	for format.StringWidth(value) > width {
+		value = value[:format.PrevGrapheme(value, len(value))]
+	}
+	return value + padSpaces(width-format.StringWidth(value)), col
+}
+
+// graphemeCount returns the number of graphemes in s
+func graphemeCount(s string) int {
+	var n int
+	for i := 0; i < len(s); i = format.NextGrapheme(s, i) {
+		n++
+	}
+	return n
+}
+
+// formStatus returns the validation error for the bottom outline, or ""
+func (t *Tile) formStatus() string {
+	if t.handler.TileType != TileType_Form {
+		return ""
+	}
+	return t.form.err
+}
+
+// == TileType_Form Handler Functions
+func fm_RenderText(t *Tile) string {
+	fm := &t.form
+	str := ClearRect(t.bounds)
+	t.curPos = t.bounds.Min
+	if len(fm.fields) == 0 {
+		return str
+	}
+	rects := t.formLayout()
+	// scroll so the focus field is visible
+	focus := rects[fm.focus]
+	if focus.Max.Y >= t.start.Y+t.Height() {
+		t.start.Y = focus.Max.Y - t.Height() + 1
+	}
+	if focus.Min.Y < t.start.Y {
+		t.start.Y = focus.Min.Y
+	}
+	off := Point{X: t.bounds.Min.X, Y: t.bounds.Min.Y - t.start.Y}
+	for i, r := range rects {
+		r = Rect{Min: Point{X: r.Min.X + off.X, Y: r.Min.Y + off.Y}, Max: Point{X: r.Max.X + off.X, Y: r.Max.Y + off.Y}}
+		if r.Min.Y < t.bounds.Min.Y || r.Max.Y > t.bounds.Max.Y || r.Max.X > t.bounds.Max.X {
+			continue // only render fields that fit
+		}
+		str += t.formRenderField(i, r)
+	}
+	return str
+}
+
+// formRenderField renders field i into screen rect r, and sets the cursor if it is the focus field
+func (t *Tile) formRenderField(i int, r Rect) string {
+	fm := &t.form
+	f := &fm.fields[i]
+	focus := i == fm.focus
+	var style []SGRType
+	if i == fm.errField {
+		style = append(style, SGR_FgRed)
+	}
+	label := f.Label
+	if focus && (f.Type == Field_Text || f.Type == Field_Password || f.Type == Field_Dropdown) {
+		style = append(style, SGR_Bold)
+	}
+	w := r.Max.X - r.Min.X + 1
+	var str string
+	switch f.Type {
+	case Field_Text, Field_Password, Field_Dropdown:
+		chars := SingleBox
+		if focus {
+			chars = DoubleBox
+		}
+		box := Rect{Min: r.Min, Max: Point{X: r.Max.X, Y: r.Min.Y + 2}}
+		if label != "" {
+			label = " " + label + " "
+		}
+		str += Box(box, chars, label, style...)
+		str += CUP(r.Min.X+1, r.Min.Y+1)
+		if f.Type == Field_Dropdown {
+			value := format.FormatTextClipCol(f.Value, w-4, 1, 0)[0]
+			str += value + " ▼"
+			t.formCursor(focus, Point{X: r.Min.X + 1, Y: r.Min.Y + 1})
+			if focus && fm.open {
+				for j, o := range f.Options {
+					o = format.FormatTextClipCol("  "+o, w, 1, 0)[0]
+					str += CUP(r.Min.X, r.Min.Y+3+j)
+					if j == fm.option {
+						str += SGR(SGR_Negative) + o + SGR(SGR_Off)
+						t.formCursor(focus, Point{X: r.Min.X, Y: r.Min.Y + 3 + j})
+					} else {
+						str += o
+					}
+				}
+			}
+			break
+		}
+		text, col := t.formText(f, focus, w-2)
+		str += text
+		t.formCursor(focus, Point{X: r.Min.X + 1 + col, Y: r.Min.Y + 1})
+	case Field_Checkbox:
+		box := "[ ] "
+		if f.Checked {
+			box = "[x] "
+		}
+		str += CUP(r.Min.X, r.Min.Y) + t.formLabel(box+label, w, focus, style)
+		t.formCursor(focus, Point{X: r.Min.X + 1, Y: r.Min.Y})
+	case Field_Radio:
+		str += CUP(r.Min.X, r.Min.Y) + t.formLabel(label, w, focus, style)
+		t.formCursor(focus, r.Min)
+		for j, o := range f.Options {
+			mark := "  ( ) "
+			if o == f.Value {
+				mark = "  (*) "
+				t.formCursor(focus, Point{X: r.Min.X + 3, Y: r.Min.Y + 1 + j})
+			}
+			str += CUP(r.Min.X, r.Min.Y+1+j) + format.FormatTextClipCol(mark+o, w, 1, 0)[0]
+		}
+	case Field_Button:
+		str += CUP(r.Min.X, r.Min.Y) + t.formLabel("[ "+label+" ]", w, focus, style)
+		t.formCursor(focus, Point{X: r.Min.X + 2, Y: r.Min.Y})
+	case Field_Label:
+		str += CUP(r.Min.X, r.Min.Y) + t.formLabel(label, w, false, style)
+	}
+	return str
+}
+
+// formLabel returns text clipped to width, in reverse video for the focus field
+func (t *Tile) formLabel(text string, width int, focus bool, style []SGRType) string {
+	text = format.FormatTextClipCol(text, width, 1, 0)[0]
+	text = strings.TrimRight(text, " ")
+	if focus {
+		style = append(style, SGR_Negative)
+	}
+	if len(style) == 0 {
+		return text
+	}
+	return SGR(style...) + text + SGR(SGR_Off)
+}
+
+// formCursor sets the tile cursor for the focus field
+func (t *Tile) formCursor(focus bool, p Point) {
+	if focus {
+		t.curPos = p
+	}
+}
+
+// fm_KeyPress moves between fields, edits them and submits the form
+// KeyTab, KeyBackTab: next, previous field
+// KeyUp, KeyDown: previous, next option in a radio group or open dropdown, otherwise previous, next field
+// KeySpace: toggle a checkbox, open or close a dropdown, press a button
+// KeyEnter: choose the option of an open dropdown, press a button, otherwise next field
+// KeyEscape: close an open dropdown
+// Text fields use the line editing keys, see TileType_ScrollUp
+func fm_KeyPress(t *Tile, r rune) bool {
+	fm := &t.form
+	if len(fm.fields) == 0 {
+		return false
+	}
+	t.setDirty()
+	if fm.dialog {
+		if r == KeyEscape && !fm.open && fm.callback != nil {
+			return fm.callback("cancel", t.formValues())
+		}
+		if r == KeyEnter && fm.fields[fm.focus].Type == Field_Text {
+			for i := range fm.fields {
+				if fm.fields[i].Type == Field_Button {
+					t.formFocus(i)
+					break
+				}
+			}
+		}
+	}
+	f := &fm.fields[fm.focus]
+	switch {
+	case r == KeyTab:
+		t.formFocus(fm.focus + 1)
+	case r == KeyBackTab:
+		t.formFocus(fm.focus - 1)
+	case fm.open:
+		switch r {
+		case KeyUp:
+			if fm.option > 0 {
+				fm.option--
+			}
+		case KeyDown:
+			if fm.option < len(f.Options)-1 {
+				fm.option++
+			}
+		case KeyEnter, KeySpace:
+			f.Value = f.Options[fm.option]
+			fm.open = false
+		case KeyEscape:
+			fm.open = false
+		}
+	case f.Type == Field_Radio && (r == KeyUp || r == KeyDown):
+		j := optionIndex(f)
+		if r == KeyUp && j > 0 {
+			f.Value = f.Options[j-1]
+		}
+		if r == KeyDown && j < len(f.Options)-1 {
+			f.Value = f.Options[j+1]
+		}
+	case r == KeyUp:
+		t.formFocus(fm.focus - 1)
+	case r == KeyDown:
+		t.formFocus(fm.focus + 1)
+	case f.Type == Field_Button && (r == KeyEnter || r == KeySpace):
+		if t.formValidate() && fm.callback != nil {
+			return fm.callback(fieldName(f), t.formValues())
+		}
+	case f.Type == Field_Dropdown && (r == KeyEnter || r == KeySpace):
+		if len(f.Options) > 0 {
+			fm.open = true
+			if fm.option = optionIndex(f); fm.option < 0 {
+				fm.option = 0
+			}
+		}
+	case r == KeyEnter:
+		t.formFocus(fm.focus + 1)
+	case f.Type == Field_Checkbox && r == KeySpace:
+		f.Checked = !f.Checked
+	case f.Type == Field_Text || f.Type == Field_Password:
+		if t.lineEditKey(r) {
+			f.Value = t.line
+		}
+	}
+	return false
+}
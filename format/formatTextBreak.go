@@ -3,18 +3,28 @@ package format
 import (
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
-// FormatTextBreak preserves tabs and newlines. 
-// Lines longer than width are broken at the last space before width, 
+// FormatTextBreak preserves tabs and newlines.
+// Lines longer than width are broken at the last space before width,
 // if possible, otherwise at width.
 // All lines space padded to width.
 // Each single visble rune counts toward width.
+// Tabs expand to the next multiple of tabSize.
 func FormatTextBreak(text string, width int, tabSize int) []string {
-	text = strings.Replace(text, "\r\n", "\n", -1)
-	text = strings.Replace(text, "\r", "\n", -1)
-	text = strings.Replace(text, "\t", strings.Repeat(" ", tabSize), -1)
-	preLines := strings.Split(text, "\n")
+	return FormatTextBreakTabs(text, width, NewTabs(tabSize))
+}
+
+// FormatTextBreakTabs is FormatTextBreak with custom tab stops
+func FormatTextBreakTabs(text string, width int, tabs Tabs) []string {
+	return FormatTextBreakIndent(text, width, tabs, Indent{})
+}
+
+// FormatTextBreakIndent is FormatTextBreakTabs with continuation lines
+// indented as described by indent, see Indent.
+func FormatTextBreakIndent(text string, width int, tabs Tabs, indent Indent) []string {
+	preLines := SplitLines(text, tabs)
 	blankLine := strings.Repeat(" ", width)
 	lines := make([]string, 0)
 	for _, line := range preLines {
@@ -22,37 +32,50 @@ func FormatTextBreak(text string, width int, tabSize int) []string {
 			lines = append(lines, blankLine)
 			continue
 		}
+		hang, hangWidth := indent.hang(line)
+		if hangWidth >= width {
+			hang, hangWidth = "", 0 // no room to indent
+		}
+		var seg string
+		seg, line = breakLine(line, width)
+		lines = append(lines, seg)
 		for len(line) > 0 {
-			// find last space at or before width
-			spaceIndex := -1
-			spaceCnt := -1
-			var cnt, index int
-			var r rune
-			for index, r = range line {
-				if unicode.IsPrint(r) {
-					cnt++
-					if r == ' ' {
-						spaceIndex = index
-						spaceCnt = cnt
-					}
-					if cnt >= width {
-						break
-					}
-				}
+			seg, line = breakLine(line, width-hangWidth)
+			lines = append(lines, hang+seg)
+		}
+	}
+	return lines
+}
+
+// breakLine breaks a single line segment from line, returning the segment
+// space padded to width and the remaining text.
+func breakLine(line string, width int) (string, string) {
+	blankLine := strings.Repeat(" ", width)
+	// find last space at or before width
+	spaceIndex := -1
+	spaceCnt := -1
+	var cnt, index int
+	for i, r := range line {
+		index = i
+		if unicode.IsPrint(r) {
+			cnt++
+			if r == ' ' {
+				spaceIndex = index
+				spaceCnt = cnt
 			}
-			if cnt == 0 { // no printable chars in line, add blankLine to any non-printable
-				lines = append(lines, line+blankLine)
+			if cnt >= width {
 				break
 			}
-			if spaceIndex >= 0 && cnt == width {
-				lines = append(lines, line[:spaceIndex]+strings.Repeat(" ", width-spaceCnt+1))
-				line = strings.TrimLeft(line[spaceIndex:], " ")
-				continue
-			}
-			// if we get here, then just break at width
-			lines = append(lines, line[:index+1]+strings.Repeat(" ", width-cnt))
-			line = strings.TrimLeft(line[index+1:], " ")
 		}
 	}
-	return lines
+	if cnt == 0 { // no printable chars in line, add blankLine to any non-printable
+		return line + blankLine, ""
+	}
+	if spaceIndex >= 0 && cnt == width {
+		return line[:spaceIndex] + strings.Repeat(" ", width-spaceCnt+1), strings.TrimLeft(line[spaceIndex:], " ")
+	}
+	// if we get here, then just break at width
+	_, size := utf8.DecodeRuneInString(line[index:])
+	end := index + size
+	return line[:end] + strings.Repeat(" ", width-cnt), strings.TrimLeft(line[end:], " ")
 }
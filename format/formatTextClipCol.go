@@ -10,11 +10,15 @@ import (
 // All lines space padded to width.
 // Each single visible rune counts toward width,
 // beginning at column col.
+// Tabs expand to the next multiple of tabSize, measured from column 0
+// so that tab stops stay aligned when scrolled horizontally.
 func FormatTextClipCol(text string, width int, tabSize int, col int) []string {
-	text = strings.Replace(text, "\r\n", "\n", -1)
-	text = strings.Replace(text, "\r", "\n", -1)
-	text = strings.Replace(text, "\t", strings.Repeat(" ", tabSize), -1)
-	lines := strings.Split(text, "\n")
+	return FormatTextClipColTabs(text, width, NewTabs(tabSize), col)
+}
+
+// FormatTextClipColTabs is FormatTextClipCol with custom tab stops
+func FormatTextClipColTabs(text string, width int, tabs Tabs, col int) []string {
+	lines := SplitLines(text, tabs)
 	blankLine := strings.Repeat(" ", width)
 	var b strings.Builder
 	for i, line := range lines {
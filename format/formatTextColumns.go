@@ -0,0 +1,61 @@
+package format
+
+import (
+	"strings"
+)
+
+// FormatTextColumns flows text into columns like a newspaper.
+// Text is broken as FormatTextBreakIndent to the column width, and the broken lines
+// fill each column top to bottom, with gutter spaces between columns.
+// If height > 0, lines are grouped into pages of height rows, so each page is one
+// screenful of columns. Otherwise all the text is one page.
+// The column heights of a partial (or the only) page are balanced.
+// All lines space padded to width.
+func FormatTextColumns(text string, width, columns, gutter, height int, tabs Tabs, indent Indent) []string {
+	if columns < 1 {
+		columns = 1
+	}
+	if gutter < 0 {
+		gutter = 0
+	}
+	colWidth := (width - gutter*(columns-1)) / columns
+	if colWidth < 1 {
+		return FormatTextBreakIndent(text, width, tabs, indent)
+	}
+	colLines := FormatTextBreakIndent(text, colWidth, tabs, indent)
+	pageSize := columns * height
+	if height < 1 {
+		pageSize = len(colLines)
+	}
+	spacer := strings.Repeat(" ", gutter)
+	blankCol := strings.Repeat(" ", colWidth)
+	pad := strings.Repeat(" ", width-colWidth*columns-gutter*(columns-1))
+
+	lines := make([]string, 0)
+	for p := 0; p < len(colLines); p += pageSize {
+		page := colLines[p:]
+		if len(page) > pageSize {
+			page = page[:pageSize]
+		}
+		rows := height
+		if len(page) < pageSize || height < 1 {
+			rows = (len(page) + columns - 1) / columns // balance
+		}
+		for y := 0; y < rows; y++ {
+			var b strings.Builder
+			for c := 0; c < columns; c++ {
+				if c > 0 {
+					b.WriteString(spacer)
+				}
+				if i := c*rows + y; i < len(page) {
+					b.WriteString(page[i])
+				} else {
+					b.WriteString(blankCol)
+				}
+			}
+			b.WriteString(pad)
+			lines = append(lines, b.String())
+		}
+	}
+	return lines
+}
@@ -53,3 +53,134 @@ func TestFormatVisual(t *testing.T) {
 		fmt.Printf("|%s|%d\n", line, utf8.RuneCountInString(line))
 	}
 }
+
+// go test -run TestTabs
+func TestTabs(t *testing.T) {
+	tests := []struct {
+		tabs Tabs
+		in   string
+		out  string
+	}{
+		{NewTabs(4), "a\tb", "a   b"},
+		{NewTabs(4), "abcd\tb", "abcd    b"},
+		{NewTabs(4), "\t\tx", "        x"},
+		{NewTabs(8, 2, 10), "a\tb\tc\td", "a b       c     d"},
+		{NewTabs(8, 10, 2, 10), "a\tb\tc\td", "a b       c     d"},
+	}
+	for _, tt := range tests {
+		if got := tt.tabs.Expand(tt.in); got != tt.out {
+			t.Errorf("Expand(%q) expected: %q but got: %q", tt.in, tt.out, got)
+		}
+	}
+
+	// tab stops are measured from column 0, so clipped columns stay aligned
+	lines := FormatTextClipCol("ab\tc\nabcde\tc", 4, 8, 6)
+	if lines[0] != "  c " || lines[1] != "  c " {
+		t.Errorf("FormatTextClipCol misaligned tabs: %q", lines)
+	}
+}
+
+// go test -run TestFormatIndent
+func TestFormatIndent(t *testing.T) {
+	text := "\t- Praesent in elit aliquet suscipit\n12. Nulla facilisi morbi tempus\n> quoted text that wraps around\nplain text that also wraps"
+	lines := FormatTextBreakIndent(text, 16, NewTabs(3), Indent{Hanging: true, Prefixes: QuotePrefixes})
+	printCheck(t, lines, 16)
+	expected := []string{
+		"   - Praesent   ",
+		"     in elit    ",
+		"     aliquet    ",
+		"     suscipit   ",
+		"12. Nulla       ",
+		"    facilisi    ",
+		"    morbi       ",
+		"    tempus      ",
+		"> quoted text   ",
+		"> that wraps    ",
+		"> around        ",
+		"plain text that ",
+		"also wraps      ",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines but got %d", len(expected), len(lines))
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("line %d expected: %q but got: %q", i, expected[i], line)
+		}
+	}
+
+	for width := 10; width < 200; width += 23 {
+		lines := FormatTextBreakIndent(text, width, NewTabs(3), Indent{Hanging: true})
+		printCheck(t, lines, width)
+	}
+}
+
+// go test -run TestFormatColumns
+func TestFormatColumns(t *testing.T) {
+	text := "Loremipsumdolorsitametconsecteturadipiscingelit. \n\n\nMauris        eget purus arcu. Sed quis ornare magna. \n\t- Nulla facilisi\n\t- Praesent in elit\n\t- In mi aliquet suscipit\nSuspendisse\tvel\tenim id metus iaculis pretium. Sed semper pharetra mi a varius. Vestibulum\trutrum\tultricies urna,\tvitae pretium metus ullamcorper vel.\nInteger euismod elit elit, at dictum urna auctor a. Suspendisse vitae est aliquam, euismod enim a, imperdiet ipsum. Suspendisse vel enim id metus iaculis pretium.5Ὂg̀9! ℃ᾭG\n5Ὂg̀9! ℃ᾭG\n<the end>"
+
+	for width := 10; width < 200; width += 23 {
+		for columns := 1; columns <= 4; columns++ {
+			lines := FormatTextColumns(text, width, columns, 2, 0, NewTabs(3), Indent{})
+			printCheck(t, lines, width)
+			lines = FormatTextColumns(text, width, columns, 2, 7, NewTabs(3), Indent{})
+			printCheck(t, lines, width)
+		}
+	}
+
+	// balanced: 5 lines in 2 columns is 3 rows
+	lines := FormatTextColumns("a\nb\nc\nd\ne", 5, 2, 1, 0, NewTabs(3), Indent{})
+	expected := []string{"a  d ", "b  e ", "c    "}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines but got: %q", len(expected), lines)
+	}
+	for i := range lines {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d expected: %q but got: %q", i, expected[i], lines[i])
+		}
+	}
+
+	// paged: each full page is height rows, the last page is balanced
+	lines = FormatTextColumns("a\nb\nc\nd\ne\nf", 3, 2, 1, 2, NewTabs(3), Indent{})
+	expected = []string{"a c", "b d", "e f"}
+	for i := range lines {
+		if i >= len(expected) || lines[i] != expected[i] {
+			t.Errorf("paged lines expected: %q but got: %q", expected, lines)
+			break
+		}
+	}
+}
+
+// go test -run TestGraphemes
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		in    string
+		parts []string
+		width int
+	}{
+		{"abc", []string{"a", "b", "c"}, 3},
+		{"g̀9", []string{"g̀", "9"}, 2},
+		{"日本", []string{"日", "本"}, 4},
+		{"👍🏽!", []string{"👍🏽", "!"}, 3},
+		{"🇯🇵x", []string{"🇯🇵", "x"}, 3},
+		{"👩‍💻a", []string{"👩‍💻", "a"}, 3},
+		{"a\x1b[31mb\x1b[m", []string{"a", "\x1b[31m", "b", "\x1b[m"}, 2},
+	}
+	for _, tt := range tests {
+		var parts []string
+		for i := 0; i < len(tt.in); {
+			j := NextGrapheme(tt.in, i)
+			if p := PrevGrapheme(tt.in, j); p != i {
+				t.Errorf("PrevGrapheme(%q, %d) expected: %d but got: %d", tt.in, j, i, p)
+			}
+			parts = append(parts, tt.in[i:j])
+			i = j
+		}
+		if fmt.Sprint(parts) != fmt.Sprint(tt.parts) {
+			t.Errorf("graphemes of %q expected: %q but got: %q", tt.in, tt.parts, parts)
+		}
+		if w := StringWidth(tt.in); w != tt.width {
+			t.Errorf("StringWidth(%q) expected: %d but got: %d", tt.in, tt.width, w)
+		}
+	}
+}
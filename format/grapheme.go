@@ -0,0 +1,111 @@
+package format
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Graphemes are approximated as a base rune followed by any combining marks,
+// variation selectors, emoji modifiers and zero width joiner sequences,
+// with regional indicator flag pairs and "\r\n" kept together.
+// A CSI escape sequence, such as an SGR style, is kept together as one zero width grapheme.
+
+const zwj = 0x200D // zero width joiner
+
+// isExtend returns true if r extends the previous grapheme
+func isExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == zwj ||
+		(r >= 0xFE00 && r <= 0xFE0F) || // variation selectors
+		(r >= 0x1F3FB && r <= 0x1F3FF) || // emoji skin tone modifiers
+		(r >= 0xE0020 && r <= 0xE007F) // tags
+}
+
+// isRegional returns true if r is a regional indicator, used in pairs for flags
+func isRegional(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// NextGrapheme returns the byte offset of the grapheme boundary after offset i in s
+func NextGrapheme(s string, i int) int {
+	if i >= len(s) {
+		return len(s)
+	}
+	r, size := utf8.DecodeRuneInString(s[i:])
+	j := i + size
+	if r == '\r' && j < len(s) && s[j] == '\n' {
+		return j + 1
+	}
+	if r == 0x1b && j < len(s) && s[j] == '[' { // CSI parameters up to the final byte
+		for j++; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1
+			}
+		}
+		return j
+	}
+	if isRegional(r) {
+		if r2, size2 := utf8.DecodeRuneInString(s[j:]); isRegional(r2) {
+			j += size2
+		}
+	}
+	prev := r
+	for j < len(s) {
+		r, size = utf8.DecodeRuneInString(s[j:])
+		if !isExtend(r) && prev != zwj {
+			break
+		}
+		prev = r
+		j += size
+	}
+	return j
+}
+
+// PrevGrapheme returns the byte offset of the grapheme boundary before offset i in s
+func PrevGrapheme(s string, i int) int {
+	var prev int
+	for j := 0; j < i && j < len(s); j = NextGrapheme(s, j) {
+		prev = j
+	}
+	return prev
+}
+
+// GraphemeWidth returns the display width of a single grapheme:
+// 0 if it is not printable, 2 if it is East Asian wide or an emoji, otherwise 1
+func GraphemeWidth(g string) int {
+	r, _ := utf8.DecodeRuneInString(g)
+	switch {
+	case !unicode.IsPrint(r):
+		return 0
+	case isWide(r):
+		return 2
+	}
+	return 1
+}
+
+// StringWidth returns the display width of s, see GraphemeWidth
+func StringWidth(s string) int {
+	var w int
+	for i := 0; i < len(s); {
+		j := NextGrapheme(s, i)
+		w += GraphemeWidth(s[i:j])
+		i = j
+	}
+	return w
+}
+
+// isWide returns true for the common East Asian wide, fullwidth and emoji ranges
+func isWide(r rune) bool {
+	return (r >= 0x1100 && r <= 0x115F) ||
+		(r >= 0x2E80 && r <= 0x303E) ||
+		(r >= 0x3041 && r <= 0xA4CF) ||
+		(r >= 0xAC00 && r <= 0xD7A3) ||
+		(r >= 0xF900 && r <= 0xFAFF) ||
+		(r >= 0xFE30 && r <= 0xFE4F) ||
+		(r >= 0xFF00 && r <= 0xFF60) ||
+		(r >= 0xFFE0 && r <= 0xFFE6) ||
+		(r >= 0x1F1E6 && r <= 0x1F1FF) ||
+		(r >= 0x1F300 && r <= 0x1F64F) ||
+		(r >= 0x1F900 && r <= 0x1F9FF) ||
+		(r >= 0x20000 && r <= 0x3FFFD)
+}
@@ -0,0 +1,73 @@
+package format
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Indent holds the options for indenting continuation lines of a broken line.
+// The zero value does not indent.
+// If Hanging is true, the leading whitespace and any bullet or number marker
+// of a line are detected, and continuation lines are aligned under the text.
+// Prefixes are quote or comment markers, such as "> " or "// ", that are
+// repeated at the start of continuation lines when a line begins with one.
+type Indent struct {
+	Hanging  bool
+	Prefixes []string
+}
+
+// QuotePrefixes is a common set of quote and comment prefixes for Indent
+var QuotePrefixes = []string{"> ", "// ", "# ", "-- ", "; "}
+
+// bullets are the markers recognized as a list bullet when followed by a space
+const bullets = "-*+•◦‣"
+
+// hang returns the continuation prefix for line and its width in visible runes
+func (in Indent) hang(line string) (string, int) {
+	if !in.Hanging && len(in.Prefixes) == 0 {
+		return "", 0
+	}
+	rest := strings.TrimLeft(line, " ")
+	hang := line[:len(line)-len(rest)]
+	for _, p := range in.Prefixes {
+		if p != "" && strings.HasPrefix(rest, p) {
+			hang += p
+			rest = rest[len(p):]
+			break
+		}
+	}
+	if in.Hanging {
+		trimmed := strings.TrimLeft(rest, " ")
+		hang += rest[:len(rest)-len(trimmed)]
+		if n := markerLen(trimmed); n > 0 {
+			hang += strings.Repeat(" ", utf8.RuneCountInString(trimmed[:n]))
+		}
+	}
+	return hang, utf8.RuneCountInString(hang)
+}
+
+// markerLen returns the length in bytes of a bullet or number marker
+// and its following spaces at the start of s, or 0 if there is none
+func markerLen(s string) int {
+	var n int
+	r, size := utf8.DecodeRuneInString(s)
+	switch {
+	case strings.ContainsRune(bullets, r):
+		n = size
+	case unicode.IsDigit(r):
+		for n < len(s) && n < 9 && unicode.IsDigit(rune(s[n])) {
+			n++
+		}
+		if n >= len(s) || (s[n] != '.' && s[n] != ')') {
+			return 0
+		}
+		n++
+	default:
+		return 0
+	}
+	if n >= len(s) || s[n] != ' ' {
+		return 0
+	}
+	return len(s) - len(strings.TrimLeft(s[n:], " "))
+}
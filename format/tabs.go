@@ -0,0 +1,75 @@
+package format
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Tabs holds the tab stop settings used when expanding tabs.
+// Stops are 0 based display columns in increasing order.
+// Beyond the last stop, tabs advance to the next multiple of Size.
+type Tabs struct {
+	Stops []int
+	Size  int
+}
+
+// NewTabs returns Tabs with a default size and optional custom stops, which are sorted and de-duplicated
+func NewTabs(size int, stops ...int) Tabs {
+	sorted := append([]int(nil), stops...)
+	sort.Ints(sorted)
+	var unique []int
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			unique = append(unique, s)
+		}
+	}
+	return Tabs{Stops: unique, Size: size}
+}
+
+// Next returns the column of the next tab stop after col
+func (tb Tabs) Next(col int) int {
+	for _, s := range tb.Stops {
+		if s > col {
+			return s
+		}
+	}
+	if tb.Size < 1 {
+		return col + 1
+	}
+	return (col/tb.Size + 1) * tb.Size
+}
+
+// Expand replaces each tab in a single line with spaces up to the next tab stop.
+// Each single visible rune counts as one display column.
+func (tb Tabs) Expand(line string) string {
+	if !strings.ContainsRune(line, '\t') {
+		return line
+	}
+	var b strings.Builder
+	var col int
+	for _, r := range line {
+		if r == '\t' {
+			next := tb.Next(col)
+			b.WriteString(strings.Repeat(" ", next-col))
+			col = next
+			continue
+		}
+		b.WriteRune(r)
+		if unicode.IsPrint(r) {
+			col++
+		}
+	}
+	return b.String()
+}
+
+// SplitLines normalizes line endings and splits text into lines with tabs expanded
+func SplitLines(text string, tabs Tabs) []string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = strings.Replace(text, "\r", "\n", -1)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = tabs.Expand(line)
+	}
+	return lines
+}
@@ -4,4 +4,4 @@ go 1.20
 
 require golang.org/x/term v0.6.0
 
-require golang.org/x/sys v0.6.0 // indirect
+require golang.org/x/sys v0.6.0
@@ -1,8 +1,11 @@
 package termfun
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/exyzzy/termfun/format"
 )
@@ -12,6 +15,15 @@ import (
 // TileType_ScrollDownClip renders from top to bottom and only breaks lines on newline in the text, it scrolls up, down, left, right.
 // TileType_ScrollDownClipRaw is like TileType_ScrollDownClip but without any scroll key handling.
 // TileType_ScrollUp behaves like a typical terminal, rendering bottom to top by line, it scrolls up, down.
+// TileType_List is a selectable list of items, or menu, with type-ahead filtering, see Tile.SetItems.
+// TileType_Form is a form of text fields, checkboxes, radio groups, dropdowns and buttons, see Tile.SetFields.
+// TileType_Widgets stacks progress bars, gauges, spinners and sparklines, see Tile.AddWidget.
+// TileType_Canvas renders a Canvas sized to the tile, see Tile.Canvas, keys go to the KeyCallback.
+// TileType_PTY is a terminal emulator running a program on a pseudo terminal, see Tile.StartPTY.
+// TileType_Tabs hosts child tiles as tabs named in its top outline, see Tile.AddTab.
+// TileType_StatusBar is a one line bar of left, center and right segments, see TileTerm.AddStatusBar.
+// TileType_Tree is an expandable tree of nodes with lazily loaded children, see Tile.SetRoot.
+// TileType_Hex is a hex dump of an io.ReaderAt with jump, search and selection, see Tile.SetReaderAt.
 
 type TileType int
 
@@ -20,22 +32,80 @@ const (
 	TileType_ScrollDownClip
 	TileType_ScrollDownClipRaw
 	TileType_ScrollUp
+	TileType_List
+	TileType_Form
+	TileType_Widgets
+	TileType_Canvas
+	TileType_PTY
+	TileType_Tabs
+	TileType_StatusBar
+	TileType_Tree
+	TileType_Hex
 )
 
+// Render must render the full tile bounds and set the cursor position, see Tile.RenderLines
+// KeyPress handles a key for the focus tile, returning true exits TileTerm
+// CopyLines returns the text of the tile as lines for copy mode, if nil copy mode uses the lines of the tile Buffer
 type TileHandler struct {
-	TileType TileType
-	Render   func(*Tile) string
-	KeyPress func(*Tile, rune) bool
+	TileType  TileType
+	Render    func(*Tile) string
+	KeyPress  func(*Tile, rune) bool
+	CopyLines func(*Tile) []string
 }
 
 var tileHandler = []*TileHandler{
-	{TileType: TileType_ScrollDown, Render: sd_RenderText, KeyPress: sd_KeyPress},
-	{TileType: TileType_ScrollDownClip, Render: sdc_RenderText, KeyPress: sdc_KeyPress},
-	{TileType: TileType_ScrollDownClipRaw, Render: sdc_RenderText, KeyPress: sdcr_KeyPress},
-	{TileType: TileType_ScrollUp, Render: su_RenderText, KeyPress: su_KeyPress}}
+	{TileType: TileType_ScrollDown, Render: sd_RenderText, KeyPress: sd_KeyPress, CopyLines: sd_CopyLines},
+	{TileType: TileType_ScrollDownClip, Render: sdc_RenderText, KeyPress: sdc_KeyPress, CopyLines: sdc_CopyLines},
+	{TileType: TileType_ScrollDownClipRaw, Render: sdc_RenderText, KeyPress: sdcr_KeyPress, CopyLines: sdc_CopyLines},
+	{TileType: TileType_ScrollUp, Render: su_RenderText, KeyPress: su_KeyPress, CopyLines: su_CopyLines},
+	{TileType: TileType_List, Render: li_RenderText, KeyPress: li_KeyPress, CopyLines: li_CopyLines},
+	{TileType: TileType_Form, Render: fm_RenderText, KeyPress: fm_KeyPress},
+	{TileType: TileType_Widgets, Render: wg_RenderText, KeyPress: wg_KeyPress},
+	{TileType: TileType_Canvas, Render: cv_RenderText, KeyPress: cv_KeyPress, CopyLines: cv_CopyLines},
+	{TileType: TileType_PTY, Render: pt_RenderText, KeyPress: pt_KeyPress, CopyLines: pt_CopyLines},
+	{TileType: TileType_Tabs, Render: tb_RenderText, KeyPress: tb_KeyPress},
+	{TileType: TileType_StatusBar, Render: sb_RenderText, KeyPress: sb_KeyPress},
+	{TileType: TileType_Tree, Render: tr_RenderText, KeyPress: tr_KeyPress, CopyLines: tr_CopyLines},
+	{TileType: TileType_Hex, Render: hx_RenderText, KeyPress: hx_KeyPress, CopyLines: hx_CopyLines}}
+
+// tileTypeDirect is the TileType of handlers passed to AddTileHandler, never a built in or registered TileType
+const tileTypeDirect TileType = -1
+
+// tileHandlerLock protects tileHandler from concurrent registration
+var tileHandlerLock sync.Mutex
+
+// RegisterTileHandler registers a custom tile handler and returns its new TileType
+// for use with AddTile. Render and KeyPress must not be nil.
+func RegisterTileHandler(render func(*Tile) string, keyPress func(*Tile, rune) bool) (TileType, error) {
+	if render == nil || keyPress == nil {
+		return 0, errors.New("tile handler needs Render and KeyPress")
+	}
+	tileHandlerLock.Lock()
+	defer tileHandlerLock.Unlock()
+	tt := TileType(len(tileHandler))
+	tileHandler = append(tileHandler, &TileHandler{TileType: tt, Render: render, KeyPress: keyPress})
+	return tt, nil
+}
+
+// getTileHandler returns the registered handler for a TileType
+func getTileHandler(tt TileType) (*TileHandler, error) {
+	tileHandlerLock.Lock()
+	defer tileHandlerLock.Unlock()
+	if tt < 0 || int(tt) >= len(tileHandler) {
+		return nil, fmt.Errorf("unknown TileType %d", tt)
+	}
+	return tileHandler[tt], nil
+}
 
 // Note that all render handlers must render full text boundary area, clearing as necessary
 
+// RenderLines renders lines top to bottom from the Start().Y line within the tile bounds,
+// using blank lines as needed, and places the cursor at the tile origin.
+// Each line should be space padded to the tile Width. It is a helper for custom Render handlers.
+func (t *Tile) RenderLines(lines []string) string {
+	return renderLinesDown(t, lines, sd_SetCurPosOrigin)
+}
+
 // renderLines renders top to bottom any lines that line within the tile boundary
 // using blank lines as needed
 func renderLinesDown(t *Tile, lines []string, origin func(*Tile)) string {
@@ -57,8 +127,21 @@ func renderLinesDown(t *Tile, lines []string, origin func(*Tile)) string {
 
 // == TileType_ScrollDown Handler Functions
 func sd_RenderText(t *Tile) string {
-	tabs := 3
-	lines := format.FormatTextBreak(t.buffer.String(), t.Width(), tabs)
+	var lines []string
+	t.lock.Lock()
+	text := t.buffer.String()
+	t.lock.Unlock()
+	if t.columns > 1 {
+		lines = format.FormatTextColumns(text, t.Width(), t.columns, t.gutter, t.Height(), t.tabs, t.indent)
+	} else {
+		lines = format.FormatTextBreakIndent(text, t.Width(), t.tabs, t.indent)
+	}
+	t.lineCount = followLines(text, lines)
+	t.maxCol = 0
+	t.followStart(t.lineCount)
+	t.findUpdate(lines)
+	t.scrollClamp()
+	t.findHighlight(lines, 0)
 	return (renderLinesDown(t, lines, sd_SetCurPosOrigin))
 }
 
@@ -67,38 +150,64 @@ func sd_SetCurPosOrigin(t *Tile) {
 	t.curPos.Y = t.bounds.Min.Y
 }
 
+func sd_CopyLines(t *Tile) []string {
+	text := t.Buffer()
+	if t.columns > 1 {
+		return format.FormatTextColumns(text, t.Width(), t.columns, t.gutter, t.Height(), t.tabs, t.indent)
+	}
+	return format.FormatTextBreakIndent(text, t.Width(), t.tabs, t.indent)
+}
+
 func sd_KeyPress(t *Tile, r rune) bool {
-	switch r {
-	case KeyUp:
-		if t.start.Y > 0 {
-			t.start.Y--
-			t.setDirty()
-		}
-	case KeyDown:
-		t.start.Y++
-		t.setDirty()
+	if t.findKeyPress(r) {
+		return false
 	}
+	t.followKey(r)
+	t.scrollKey(r)
 	return false // return true from any keypress handler to exit TileTerm
 }
 
 // == TileType_ScrollDownClip Handler Functions
 func sdc_RenderText(t *Tile) string {
-	tabs := 3
-	lines := format.FormatTextClipCol(t.buffer.String(), t.Width(), tabs, t.start.X)
+	var lines []string
+	t.lock.Lock()
+	text := t.buffer.String()
+	t.lock.Unlock()
+	full := format.SplitLines(text, t.tabs)
+	t.lineCount = followLines(text, full)
+	t.maxCol = 0
+	for _, line := range full {
+		if n := utf8.RuneCountInString(line); n > t.maxCol {
+			t.maxCol = n
+		}
+	}
+	t.textWidth = t.Width() - t.highlighter.gutterWidth(len(full), t.Width())
+	t.followStart(t.lineCount)
+	t.findUpdate(full)
+	t.scrollClamp()
+	if t.highlighter != nil {
+		lines = t.highlighter.Format(text, t.Width(), t.tabs, t.start.X)
+	} else {
+		lines = format.FormatTextClipColTabs(text, t.Width(), t.tabs, t.start.X)
+	}
+	t.findHighlight(lines, t.start.X-(t.Width()-t.textWidth)) // less the line number gutter
 	return (renderLinesDown(t, lines, sd_SetCurPosOrigin))
 
 }
 
+func sdc_CopyLines(t *Tile) []string {
+	return format.SplitLines(t.Buffer(), t.tabs)
+}
+
 func sdc_KeyPress(t *Tile, r rune) bool {
+	if t.findKeyPress(r) {
+		return false
+	}
+	t.followKey(r)
+	if t.scrollKey(r) {
+		return false
+	}
 	switch r {
-	case KeyUp:
-		if t.start.Y > 0 {
-			t.start.Y--
-			t.setDirty()
-		}
-	case KeyDown:
-		t.start.Y++
-		t.setDirty()
 	case KeyLeft:
 		if t.start.X > 0 {
 			t.start.X--
@@ -106,6 +215,7 @@ func sdc_KeyPress(t *Tile, r rune) bool {
 		}
 	case KeyRight:
 		t.start.X++
+		t.scrollClamp()
 		t.setDirty()
 	}
 	return false
@@ -127,11 +237,26 @@ func sdcr_KeyPress(t *Tile, r rune) bool {
 func su_RenderText(t *Tile) string {
 	var str string
 	blankLine := strings.Repeat(" ", t.Width())
-	ss := strings.Split(t.buffer.String(), "\n")
-	ss[len(ss)-1] = t.cursor + t.line // add cursor
+	prompt := t.cursor
+	t.lock.Lock()
+	ss := t.buffer.Lines()
+	if t.procRunning() { // input to a process follows its partial output line
+		prompt = ss[len(ss)-1]
+	}
+	t.lock.Unlock()
+	ss[len(ss)-1] = prompt + t.line // add cursor
+	pos := len(prompt) + t.linePos
+	if t.searching {
+		ss[len(ss)-1], pos = t.searchLine()
+	}
 	curSS := len(ss) - 1
-	var sub []string
-	curSub := -1
+	sub, at := wrapStrPos(ss[curSS], t.Width(), pos)
+	curSS--
+	curSub := len(sub) - 1
+	su_SetCurPosOrigin(t)
+	inputTop := t.curPos.Y - (len(sub) - 1)
+	t.curPos.X += at.X
+	t.curPos.Y = inputTop + at.Y
 	var newLine string
 	for y := t.bounds.Max.Y; y >= t.bounds.Min.Y; y-- {
 		str += CUP(t.bounds.Min.X, y)
@@ -140,7 +265,7 @@ func su_RenderText(t *Tile) string {
 			curSub--
 		} else {
 			if curSS >= 0 {
-				sub = wrapStr(ss[curSS], t.Width())
+				sub = wrapStr(t.tabs.Expand(ss[curSS]), t.Width())
 				curSub = len(sub) - 1
 				curSS--
 				newLine = sub[curSub]
@@ -151,32 +276,69 @@ func su_RenderText(t *Tile) string {
 		}
 		str += newLine
 	}
-	su_SetCurPosOrigin(t)
-	t.curPos.X += len(t.cursor) + len(t.line)
+	if len(t.completions) > 0 {
+		_, compAt := wrapStrPos(prompt+t.line, t.Width(), len(prompt)+t.compStart)
+		str += t.renderPopup(t.completions, t.compIndex, inputTop+compAt.Y, t.bounds.Min.X+compAt.X)
+	}
+	if t.searching {
+		str += t.renderPopup(t.searchMatches, t.searchIndex, inputTop, t.bounds.Min.X)
+	}
+	if t.curPos.Y < t.bounds.Min.Y {
+		t.curPos.Y = t.bounds.Min.Y
+	}
 	return str
 }
 
 // wrapStr returns single string s as []strings that are clipped or space padded
 func wrapStr(s string, ln int) []string {
-	var str string
+	out, _ := wrapStrPos(s, ln, -1)
+	return out
+}
+
+// wrapStrPos is wrapStr that also returns the row and column of byte offset pos in s.
+// Lines wrap between graphemes by display width.
+// If pos is at the end of a full last row, a blank row is added for it.
+func wrapStrPos(s string, ln int, pos int) ([]string, Point) {
 	var out []string
+	var at Point
 	if ln == 0 {
-		return out
+		return out, at
 	}
-	str = s
-	if len(str) == 0 {
-		str = " " //always one line min
+	var row strings.Builder
+	var col int
+	for i := 0; i < len(s); {
+		j := format.NextGrapheme(s, i)
+		w := format.GraphemeWidth(s[i:j])
+		if col+w > ln && col > 0 {
+			out = append(out, row.String()+padSpaces(ln-col))
+			row.Reset()
+			col = 0
+		}
+		if i == pos {
+			at = Point{X: col, Y: len(out)}
+		}
+		row.WriteString(s[i:j])
+		col += w
+		i = j
 	}
-	for len(str) > 0 {
-		if len(str) > ln {
-			out = append(out, str[:ln])
-			str = str[ln:]
-		} else {
-			out = append(out, str+strings.Repeat(" ", ln-len(str)))
-			str = ""
+	if pos == len(s) {
+		if col == ln && col > 0 {
+			out = append(out, row.String())
+			row.Reset()
+			col = 0
 		}
+		at = Point{X: col, Y: len(out)}
 	}
-	return out
+	out = append(out, row.String()+padSpaces(ln-col)) //always one line min
+	return out, at
+}
+
+// padSpaces returns n spaces, or "" if n < 1
+func padSpaces(n int) string {
+	if n < 1 {
+		return ""
+	}
+	return strings.Repeat(" ", n)
 }
 
 func su_SetCurPosOrigin(t *Tile) {
@@ -187,7 +349,31 @@ func su_SetCurPosOrigin(t *Tile) {
 // returning true from any LineCallback will exit TileTerm
 type LineCallback func(string) bool
 
+func su_CopyLines(t *Tile) []string {
+	lines := format.SplitLines(stripCSI(t.Buffer()), t.tabs)
+	lines[len(lines)-1] = t.cursor + t.line
+	return lines
+}
+
 func su_KeyPress(t *Tile, r rune) bool {
+	if t.searching {
+		t.setDirty()
+		if t.searchKey(r) {
+			return false
+		}
+	}
+	if t.procInput(r) {
+		return false
+	}
+	if (r == KeyTab || r == KeyBackTab) && t.completionCallback != nil {
+		t.complete(r)
+		t.setDirty()
+		return false
+	}
+	if t.completions != nil {
+		t.completeClear()
+		t.setDirty()
+	}
 	switch r {
 	case KeyEnter:
 		if t.lineCallback != nil {
@@ -195,11 +381,14 @@ func su_KeyPress(t *Tile, r rune) bool {
 				return true
 			}
 			t.historyIndex = -1
-			t.history.Add(t.line)
+			t.addHistory(t.line)
 
-			t.line = ""
+			t.setLine("")
 		}
-	// case KeyLeft, KeyRight:
+
+	case CtrlR:
+		t.searchStart()
+		t.setDirty()
 
 	case KeyUp:
 		entry, ok := t.history.NthPreviousEntry(t.historyIndex + 1)
@@ -227,13 +416,10 @@ func su_KeyPress(t *Tile, r rune) bool {
 			}
 		}
 
-	case KeyBackspace:
-		if len(t.line) > 0 {
-			t.line = t.line[:len(t.line)-1]
-		}
-
 	default:
-		t.line += fmt.Sprintf("%c", r)
+		if t.lineEditKey(r) {
+			t.setDirty()
+		}
 	}
 	return false
 }
@@ -0,0 +1,469 @@
+package termfun
+
+// hexview.go implements TileType_Hex, a hex dump of an io.ReaderAt in offset, hex and ASCII columns.
+// Only the rows in view are read on each render, and search reads in chunks, so the data is never
+// loaded whole and a large file can be viewed with an *os.File. The bytes per row fit the tile width.
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// hexChunk is the size of the reads when searching
+const hexChunk = 64 * 1024
+
+// hexState holds the state of a TileType_Hex tile
+type hexState struct {
+	r       io.ReaderAt
+	size    int64
+	top     int64    // offset of the first row in view
+	cursor  int64    // offset of the cursor byte
+	perRow  int      // bytes per row of the last render
+	marking bool     // selecting from mark to the cursor
+	mark    int64    // offset where selecting started
+	sel     [2]int64 // selected range, start and end exclusive, empty if equal
+	typing  rune     // '/' while typing a search pattern, 'g' while typing an offset, or 0
+	input   string   // text typed
+	pattern []byte   // last search pattern, or nil
+	err     string   // error or message for the bottom outline, or ""
+}
+
+// SetReaderAt sets the data of a TileType_Hex tile, size bytes of r, and moves the cursor to the start
+// r is read as the tile renders and searches, it must stay open while it is shown
+func (t *Tile) SetReaderAt(r io.ReaderAt, size int64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Hex {
+		return errors.New("Handler.TileType does not support ReaderAt")
+	}
+	if size < 0 {
+		return errors.New("negative size")
+	}
+	t.hex = hexState{r: r, size: size, perRow: t.hex.perRow}
+	t.dirty = true
+	return nil
+}
+
+// SetHexOffset moves the cursor of a TileType_Hex tile to offset, and scrolls it into view
+func (t *Tile) SetHexOffset(offset int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.hexMove(offset)
+	t.dirty = true
+}
+
+// HexOffset returns the offset of the cursor of a TileType_Hex tile
+func (t *Tile) HexOffset() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.hex.cursor
+}
+
+// SetHexSelection highlights bytes start up to end, exclusive, in both panes of a TileType_Hex tile
+// and moves the cursor to start, an empty range clears the selection
+func (t *Tile) SetHexSelection(start, end int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	h := &t.hex
+	h.marking = false
+	h.sel = [2]int64{0, 0}
+	if start < end {
+		t.hexMove(start)
+		if end > h.size {
+			end = h.size
+		}
+		h.sel = [2]int64{h.cursor, end}
+	}
+	t.dirty = true
+}
+
+// HexSelection returns the selected range of a TileType_Hex tile, start and end exclusive, equal if none
+func (t *Tile) HexSelection() (int64, int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.hex.sel[0], t.hex.sel[1]
+}
+
+// hexMove moves the cursor to offset within the data, extending the selection while marking
+func (t *Tile) hexMove(offset int64) {
+	h := &t.hex
+	if offset >= h.size {
+		offset = h.size - 1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	h.cursor = offset
+	if h.marking {
+		if h.mark <= offset {
+			h.sel = [2]int64{h.mark, offset + 1}
+		} else {
+			h.sel = [2]int64{offset, h.mark + 1}
+		}
+	}
+}
+
+// hexDigits returns the digits of the offset column for size bytes, at least 8
+func hexDigits(size int64) int {
+	digits := len(strconv.FormatInt(size, 16))
+	if digits < 8 {
+		digits = 8
+	}
+	return digits
+}
+
+// hexRowWidth returns the columns of a row of n bytes: offset, hex bytes with a gap every 8, and ASCII
+func hexRowWidth(n, digits int) int {
+	return digits + 2 + n*3 - 1 + (n-1)/8 + 2 + n + 2
+}
+
+// hexRowBytes returns the most bytes per row that fit width columns, a multiple of 8 if there is room, at least 1
+func hexRowBytes(width, digits int) int {
+	for n := 256; n > 1; n-- {
+		if (n < 8 || n%8 == 0) && hexRowWidth(n, digits) <= width {
+			return n
+		}
+	}
+	return 1
+}
+
+// hexASCII returns the ASCII pane character of a byte, '.' if it is not printable
+func hexASCII(b byte) byte {
+	if b < 0x20 || b > 0x7e {
+		return '.'
+	}
+	return b
+}
+
+// hexStyle returns the SGR of the byte at offset: negative if selected, underlined at the cursor
+func (h *hexState) hexStyle(offset int64) []SGRType {
+	var style []SGRType
+	if offset >= h.sel[0] && offset < h.sel[1] {
+		style = append(style, SGR_Negative)
+	}
+	if offset == h.cursor {
+		style = append(style, SGR_Underline, SGR_Bold)
+	}
+	return style
+}
+
+// hexRow returns the row of data at offset, in n bytes per row, styled if styled is true
+func (h *hexState) hexRow(offset int64, data []byte, n, digits int, styled bool) string {
+	var hexPane, asciiPane strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			hexPane.WriteByte(' ')
+			if i%8 == 0 {
+				hexPane.WriteByte(' ')
+			}
+		}
+		if i >= len(data) {
+			hexPane.WriteString("  ")
+			asciiPane.WriteByte(' ')
+			continue
+		}
+		style := h.hexStyle(offset + int64(i))
+		if !styled || style == nil {
+			fmt.Fprintf(&hexPane, "%02x", data[i])
+			asciiPane.WriteByte(hexASCII(data[i]))
+			continue
+		}
+		fmt.Fprintf(&hexPane, "%s%02x%s", SGR(style...), data[i], SGR(SGR_Off))
+		fmt.Fprintf(&asciiPane, "%s%c%s", SGR(style...), hexASCII(data[i]), SGR(SGR_Off))
+	}
+	return fmt.Sprintf("%0*x  %s  |%s|", digits, offset, hexPane.String(), asciiPane.String())
+}
+
+// hexRows reads the rows in view, scrolling so the cursor is visible, and returns them
+func (t *Tile) hexRows(styled bool) []string {
+	h := &t.hex
+	digits := hexDigits(h.size)
+	h.perRow = hexRowBytes(t.Width(), digits)
+	rows := int64(t.Height())
+	per := int64(h.perRow)
+	// scroll so the cursor row is visible
+	top, row, last := h.top/per, h.cursor/per, (h.size-1)/per
+	if row < top {
+		top = row
+	}
+	if row >= top+rows {
+		top = row - rows + 1
+	}
+	if top > last-rows+1 {
+		top = last - rows + 1
+	}
+	if top < 0 {
+		top = 0
+	}
+	h.top = top * per
+	t.start.Y = int(top)
+	if h.r == nil || h.size == 0 {
+		return nil
+	}
+	n := rows * per
+	if n > h.size-h.top {
+		n = h.size - h.top
+	}
+	buf := make([]byte, n)
+	m, err := h.r.ReadAt(buf, h.top)
+	if err != nil && err != io.EOF {
+		h.err = err.Error()
+	}
+	buf = buf[:m]
+	var lines []string
+	for i := 0; i < len(buf); i += h.perRow {
+		end := i + h.perRow
+		if end > len(buf) {
+			end = len(buf)
+		}
+		lines = append(lines, h.hexRow(h.top+int64(i), buf[i:end], h.perRow, digits, styled))
+	}
+	return lines
+}
+
+// hexPattern returns the bytes of a search pattern: hex digits, spaces allowed, or else the text,
+// quote the text with " if it looks like hex digits
+func hexPattern(s string) []byte {
+	if unq, err := strconv.Unquote(s); err == nil && strings.HasPrefix(s, `"`) {
+		return []byte(unq)
+	}
+	if b, err := hex.DecodeString(strings.Join(strings.Fields(s), "")); err == nil && len(b) > 0 {
+		return b
+	}
+	return []byte(s)
+}
+
+// hexFind returns the offset of the first match of p starting within start up to end, or -1
+func (h *hexState) hexFind(p []byte, start, end int64) (int64, error) {
+	if end > h.size {
+		end = h.size
+	}
+	buf := make([]byte, hexChunk+len(p)-1)
+	for pos := start; pos+int64(len(p)) <= end; pos += hexChunk {
+		n := int64(len(buf))
+		if pos+n > end {
+			n = end - pos
+		}
+		m, err := h.r.ReadAt(buf[:n], pos)
+		if err != nil && err != io.EOF {
+			return -1, err
+		}
+		if i := bytes.Index(buf[:m], p); i >= 0 {
+			return pos + int64(i), nil
+		}
+	}
+	return -1, nil
+}
+
+// hexFindLast returns the offset of the last match of p within start up to end, or -1
+func (h *hexState) hexFindLast(p []byte, start, end int64) (int64, error) {
+	if end > h.size {
+		end = h.size
+	}
+	buf := make([]byte, hexChunk+len(p)-1)
+	for hi := end; hi-start >= int64(len(p)); {
+		lo := hi - int64(len(buf))
+		if lo < start {
+			lo = start
+		}
+		m, err := h.r.ReadAt(buf[:hi-lo], lo)
+		if err != nil && err != io.EOF {
+			return -1, err
+		}
+		if i := bytes.LastIndex(buf[:m], p); i >= 0 {
+			return lo + int64(i), nil
+		}
+		if lo == start {
+			break
+		}
+		hi = lo + int64(len(p)) - 1
+	}
+	return -1, nil
+}
+
+// hexSearch moves the cursor to the next (1) or previous (-1) match of the pattern, wrapping around,
+// from the cursor itself for 0, and selects it
+func (t *Tile) hexSearch(dir int) {
+	h := &t.hex
+	p := h.pattern
+	if len(p) == 0 || h.r == nil {
+		return
+	}
+	var at int64
+	var err error
+	lp := int64(len(p))
+	switch dir {
+	case 1, 0:
+		from := h.cursor + int64(dir)
+		at, err = h.hexFind(p, from, h.size)
+		if at < 0 && err == nil {
+			at, err = h.hexFind(p, 0, from+lp-1)
+		}
+	default:
+		at, err = h.hexFindLast(p, 0, h.cursor+lp-1)
+		if at < 0 && err == nil {
+			at, err = h.hexFindLast(p, h.cursor, h.size) // back to the cursor itself if it is the only match
+		}
+	}
+	switch {
+	case err != nil:
+		h.err = err.Error()
+	case at < 0:
+		h.err = "no match"
+	default:
+		h.marking = false
+		t.hexMove(at)
+		h.sel = [2]int64{at, at + lp}
+	}
+}
+
+// hexEnter acts on the typed search pattern or offset
+func (t *Tile) hexEnter() {
+	h := &t.hex
+	switch h.typing {
+	case '/':
+		if h.input != "" {
+			h.pattern = hexPattern(h.input)
+			t.hexSearch(0)
+		}
+	case 'g':
+		offset, err := strconv.ParseInt(strings.TrimSpace(h.input), 0, 64)
+		if err != nil {
+			h.err = "bad offset"
+			break
+		}
+		t.hexMove(offset)
+	}
+	h.typing, h.input = 0, ""
+}
+
+// hexStatus returns the typed text, or a message, and the cursor position for the bottom outline, or ""
+func (t *Tile) hexStatus() string {
+	h := &t.hex
+	if t.handler.TileType != TileType_Hex {
+		return ""
+	}
+	switch {
+	case h.typing == '/':
+		return "/" + h.input + "_"
+	case h.typing == 'g':
+		return "goto " + h.input + "_"
+	}
+	status := fmt.Sprintf("0x%x/0x%x", h.cursor, h.size)
+	if n := h.sel[1] - h.sel[0]; n > 0 {
+		status = fmt.Sprintf("sel %d %s", n, status)
+	}
+	if h.err != "" {
+		status = h.err + " " + status
+	}
+	return status
+}
+
+// == TileType_Hex Handler Functions
+func hx_RenderText(t *Tile) string {
+	lines := t.hexRows(true)
+	var str string
+	for row := 0; row < t.Height(); row++ {
+		str += CUP(t.bounds.Min.X, t.bounds.Min.Y+row)
+		if row >= len(lines) {
+			str += padSpaces(t.Width())
+			continue
+		}
+		line := lines[row]
+		// rows are narrower than the tile, unless it is too narrow for one byte
+		if w := hexRowWidth(t.hex.perRow, hexDigits(t.hex.size)); w < t.Width() {
+			line += padSpaces(t.Width() - w)
+		} else if w > t.Width() {
+			line = format.FormatTextClipCol(line, t.Width(), 1, 0)[0] + SGR(SGR_Off)
+		}
+		str += line
+	}
+	t.lineCount = int((t.hex.size + int64(t.hex.perRow) - 1) / int64(t.hex.perRow))
+	i := int((t.hex.cursor - t.hex.top) % int64(t.hex.perRow))
+	x := t.bounds.Min.X + hexDigits(t.hex.size) + 2 + i*3 + i/8 // the cursor byte in the hex pane
+	if x > t.bounds.Max.X {
+		x = t.bounds.Max.X
+	}
+	t.curPos = Point{X: x, Y: t.bounds.Min.Y + int((t.hex.cursor-t.hex.top)/int64(t.hex.perRow))}
+	return str
+}
+
+// hx_CopyLines returns the rows in view without styles
+func hx_CopyLines(t *Tile) []string {
+	return t.hexRows(false)
+}
+
+// hx_KeyPress moves the cursor, jumps, searches and selects
+// KeyUp, KeyDown, KeyLeft, KeyRight: move the cursor a row, a byte
+// KeyPgUp, KeyPgDn, KeyHome, KeyEnd: move the cursor a page, to the start, to the end
+// g: type an offset, decimal or 0x hex, KeyEnter jumps to it
+// /: type a pattern, hex bytes or text, KeyEnter finds it from the cursor, n, N: next, previous match
+// KeySpace: start or stop selecting from the cursor, KeyEscape: clear the selection
+func hx_KeyPress(t *Tile, r rune) bool {
+	h := &t.hex
+	t.setDirty()
+	h.err = ""
+	if h.typing != 0 {
+		switch r {
+		case KeyEnter:
+			t.hexEnter()
+		case KeyEscape, CtrlG:
+			h.typing, h.input = 0, ""
+		case KeyBackspace, CtrlH:
+			if rs := []rune(h.input); len(rs) > 0 {
+				h.input = string(rs[:len(rs)-1])
+			}
+		default:
+			if unicode.IsPrint(r) {
+				h.input += string(r)
+			}
+		}
+		return false
+	}
+	per := int64(h.perRow)
+	if per == 0 {
+		per = 16 // before the first render
+	}
+	switch r {
+	case KeyUp:
+		t.hexMove(h.cursor - per)
+	case KeyDown:
+		t.hexMove(h.cursor + per)
+	case KeyLeft:
+		t.hexMove(h.cursor - 1)
+	case KeyRight:
+		t.hexMove(h.cursor + 1)
+	case KeyPgUp:
+		t.hexMove(h.cursor - per*int64(t.Height()))
+	case KeyPgDn:
+		t.hexMove(h.cursor + per*int64(t.Height()))
+	case KeyHome:
+		t.hexMove(0)
+	case KeyEnd:
+		t.hexMove(h.size - 1)
+	case 'g', '/':
+		h.typing = r
+	case 'n':
+		t.hexSearch(1)
+	case 'N':
+		t.hexSearch(-1)
+	case KeySpace:
+		h.marking = !h.marking
+		if h.marking {
+			h.mark = h.cursor
+			t.hexMove(h.cursor)
+		}
+	case KeyEscape:
+		h.marking = false
+		h.sel = [2]int64{0, 0}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package termfun
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// hexTestData returns size bytes of zeros with pattern p at each offset of at
+func hexTestData(size int, p []byte, at ...int) *hexState {
+	data := make([]byte, size)
+	for _, a := range at {
+		copy(data[a:], p)
+	}
+	return &hexState{r: bytes.NewReader(data), size: int64(size)}
+}
+
+// go test -run TestHexFind
+func TestHexFind(t *testing.T) {
+	p := []byte("needle")
+	size := 3*hexChunk + 100
+	tests := []struct {
+		name       string
+		at         []int // offsets of the matches in the data
+		start, end int64
+		first      int64 // expected hexFind
+		last       int64 // expected hexFindLast
+	}{
+		{"none", nil, 0, int64(size), -1, -1},
+		{"offset 0", []int{0}, 0, int64(size), 0, 0},
+		{"end of data", []int{size - len(p)}, 0, int64(size), int64(size - len(p)), int64(size - len(p))},
+		{"across a chunk", []int{hexChunk - 3}, 0, int64(size), hexChunk - 3, hexChunk - 3},
+		{"across the last chunk", []int{2*hexChunk - 1}, 0, int64(size), 2*hexChunk - 1, 2*hexChunk - 1},
+		{"ends on a chunk", []int{hexChunk - len(p)}, 0, int64(size), int64(hexChunk - len(p)), int64(hexChunk - len(p))},
+		{"several", []int{10, hexChunk - 2, size - len(p)}, 0, int64(size), 10, int64(size - len(p))},
+		{"within start, end", []int{10, hexChunk - 2, size - len(p)}, 11, int64(size - 1), hexChunk - 2, hexChunk - 2},
+		{"cut by end", []int{hexChunk - 2}, 0, hexChunk + 3, -1, -1},
+		{"start at a match", []int{hexChunk - 2}, hexChunk - 2, int64(size), hexChunk - 2, hexChunk - 2},
+		{"start after a match", []int{hexChunk - 2}, hexChunk - 1, int64(size), -1, -1},
+	}
+	for _, tt := range tests {
+		h := hexTestData(size, p, tt.at...)
+		first, err := h.hexFind(p, tt.start, tt.end)
+		if err != nil || first != tt.first {
+			t.Errorf("%s: hexFind expected: %d but got: %d, %v", tt.name, tt.first, first, err)
+		}
+		last, err := h.hexFindLast(p, tt.start, tt.end)
+		if err != nil || last != tt.last {
+			t.Errorf("%s: hexFindLast expected: %d but got: %d, %v", tt.name, tt.last, last, err)
+		}
+	}
+}
+
+// go test -run TestHexSearch
+func TestHexSearch(t *testing.T) {
+	p := []byte{0xde, 0xad}
+	size := 2*hexChunk + 10
+	matches := []int{0, hexChunk - 1, size - len(p)}
+	tests := []struct {
+		name   string
+		cursor int64
+		dir    int
+		want   int64
+	}{
+		{"from the cursor", 0, 0, 0},
+		{"from before", 1, 0, hexChunk - 1},
+		{"next", 0, 1, hexChunk - 1},
+		{"next at the end", hexChunk - 1, 1, int64(size - len(p))},
+		{"next wraps", int64(size - len(p)), 1, 0},
+		{"from the cursor wraps", int64(size - 1), 0, 0},
+		{"previous", int64(size - len(p)), -1, hexChunk - 1},
+		{"previous at the start", hexChunk - 1, -1, 0},
+		{"previous wraps", 0, -1, int64(size - len(p))},
+	}
+	for _, tt := range tests {
+		tile := &Tile{handler: tileHandler[TileType_Hex]}
+		tile.hex = *hexTestData(size, p, matches...)
+		tile.hex.cursor = tt.cursor
+		tile.hex.pattern = p
+		tile.hexSearch(tt.dir)
+		if tile.hex.cursor != tt.want || tile.hex.sel != [2]int64{tt.want, tt.want + int64(len(p))} {
+			t.Errorf("%s: expected: %d but got: %d, %v %s", tt.name, tt.want, tile.hex.cursor, tile.hex.sel, tile.hex.err)
+		}
+	}
+
+	// only one match, found again from itself
+	tile := &Tile{handler: tileHandler[TileType_Hex]}
+	tile.hex = *hexTestData(size, p, hexChunk-1)
+	tile.hex.cursor, tile.hex.pattern = hexChunk-1, p
+	for _, dir := range []int{1, -1} {
+		tile.hexSearch(dir)
+		if tile.hex.cursor != hexChunk-1 || tile.hex.err != "" {
+			t.Errorf("one match %d: expected: %d but got: %d %s", dir, hexChunk-1, tile.hex.cursor, tile.hex.err)
+		}
+	}
+	tile.hex.pattern = []byte("none")
+	tile.hexSearch(1)
+	if tile.hex.err != "no match" {
+		t.Errorf("expected no match but got: %q", tile.hex.err)
+	}
+}
+
+// go test -run TestHexCursor
+func TestHexCursor(t *testing.T) {
+	tile := &Tile{handler: tileHandler[TileType_Hex], bounds: Rect{Min: Point{X: 3, Y: 2}, Max: Point{X: 80, Y: 6}}}
+	tile.SetReaderAt(strings.NewReader(strings.Repeat("x", 100)), 100)
+	for _, tt := range []struct {
+		cursor int64
+		x, y   int
+	}{
+		{0, 3 + 10, 2}, {1, 3 + 13, 2}, {8, 3 + 10 + 8*3 + 1, 2}, {15, 3 + 10 + 15*3 + 1, 2}, {17, 3 + 13, 3},
+	} {
+		tile.hex.cursor = tt.cursor
+		hx_RenderText(tile)
+		if tile.curPos != (Point{X: tt.x, Y: tt.y}) {
+			t.Errorf("cursor %d expected: %d,%d but got: %v", tt.cursor, tt.x, tt.y, tile.curPos)
+		}
+	}
+}
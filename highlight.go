@@ -0,0 +1,119 @@
+package termfun
+
+// highlight.go renders syntax highlighted text for TileType_ScrollDownClip tiles
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/exyzzy/termfun/format"
+	"github.com/exyzzy/termfun/syntax"
+)
+
+// Theme maps each syntax class to the SGR style used to render it
+type Theme map[syntax.Class][]SGRType
+
+// DefaultTheme is used when a Highlighter has no Theme
+var DefaultTheme = Theme{
+	syntax.Class_Keyword:  {SGR_FgBlue, SGR_Bold},
+	syntax.Class_Type:     {SGR_FgCyan},
+	syntax.Class_String:   {SGR_FgGreen},
+	syntax.Class_Number:   {SGR_FgMagenta},
+	syntax.Class_Comment:  {SGR_Faint, SGR_Italic},
+	syntax.Class_Operator: {SGR_FgYellow},
+	syntax.Class_Key:      {SGR_FgCyan, SGR_Bold},
+	syntax.Class_Variable: {SGR_FgRed},
+}
+
+// Highlighter formats source text as styled lines
+// Lexer: which syntax.Lexer classifies the text, or nil for plain text
+// Theme: styles for the classes, or nil for DefaultTheme
+// LineNumbers: if true, show a line number gutter that does not scroll horizontally
+type Highlighter struct {
+	Lexer       syntax.Lexer
+	Theme       Theme
+	LineNumbers bool
+}
+
+// hlCell is a single display column of highlighted text
+type hlCell struct {
+	r     rune
+	class syntax.Class
+}
+
+// gutterWidth returns the width of the line number gutter for n lines in width columns, or 0 for none
+func (h *Highlighter) gutterWidth(n, width int) int {
+	if h == nil || !h.LineNumbers {
+		return 0
+	}
+	gutter := len(fmt.Sprint(n)) + 1
+	if gutter >= width {
+		return 0
+	}
+	return gutter
+}
+
+// Format returns the text as lines clipped to width starting at column col,
+// like format.FormatTextClipColTabs, with SGR styles applied to each token.
+// Each line holds exactly width visible runes and ends with styles off.
+func (h *Highlighter) Format(text string, width int, tabs format.Tabs, col int) []string {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = strings.Replace(text, "\r", "\n", -1)
+	var classes []syntax.Class
+	if h.Lexer != nil {
+		classes = syntax.Classes(text, h.Lexer.Lex(text))
+	}
+	theme := h.Theme
+	if theme == nil {
+		theme = DefaultTheme
+	}
+
+	var offset int
+	srcLines := strings.Split(text, "\n")
+	gutter := h.gutterWidth(len(srcLines), width)
+	lines := make([]string, len(srcLines))
+	for n, line := range srcLines {
+		// expand the line into cells, tabs become spaces of the same class
+		var cells []hlCell
+		for i, r := range line {
+			class := syntax.Class_Text
+			if classes != nil {
+				class = classes[offset+i]
+			}
+			if r == '\t' {
+				for next := tabs.Next(len(cells)); len(cells) < next; {
+					cells = append(cells, hlCell{' ', class})
+				}
+			} else if unicode.IsPrint(r) {
+				cells = append(cells, hlCell{r, class})
+			}
+		}
+		offset += len(line) + 1
+
+		var b strings.Builder
+		if gutter > 0 {
+			b.WriteString(SGR(SGR_Faint))
+			fmt.Fprintf(&b, "%*d ", gutter-1, n+1)
+			b.WriteString(SGR(SGR_Off))
+		}
+		current := syntax.Class_Text
+		for x := col; x < col+width-gutter; x++ {
+			c := hlCell{' ', syntax.Class_Text}
+			if x < len(cells) {
+				c = cells[x]
+			}
+			if c.class != current {
+				if current != syntax.Class_Text {
+					b.WriteString(SGR(SGR_Off))
+				}
+				b.WriteString(SGR(theme[c.class]...))
+				current = c.class
+			}
+			b.WriteRune(c.r)
+		}
+		b.WriteString(SGR(SGR_Off))
+		lines[n] = b.String()
+	}
+	return lines
+}
@@ -0,0 +1,168 @@
+package termfun
+
+// history.go supports persistent history and reverse incremental search for TileType_ScrollUp
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// History holds the history settings for a TileType_ScrollUp tile
+// File: file to load history from and save it to, or "" to not persist
+// Size: max number of entries kept, 0 for the default of 100
+// IgnoreDups: if true, a line equal to the previous entry is not added
+// IgnoreSpace: if true, lines that begin with a space are not added
+type History struct {
+	File        string
+	Size        int
+	IgnoreDups  bool
+	IgnoreSpace bool
+}
+
+// defaultHistorySize is the History Size if none is set
+const defaultHistorySize = 100
+
+// SetHistory sets the history settings for TileType_ScrollUp and loads any existing history File
+// A History File that does not exist yet is not an error, it is created on the next entry
+func (t *Tile) SetHistory(h History) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if h.Size < 1 {
+		h.Size = defaultHistorySize
+	}
+	t.historyConfig = h
+	t.history.SetMax(h.Size)
+	t.historyIndex = -1
+	if h.File == "" {
+		return nil
+	}
+	f, err := os.Open(h.File)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		t.history.Add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// SaveHistory writes the history to the History File, oldest first
+// It is called after each entry, so it is only needed to check for errors
+func (t *Tile) SaveHistory() error {
+	if t.historyConfig.File == "" {
+		return nil
+	}
+	var b strings.Builder
+	for n := t.history.Len() - 1; n >= 0; n-- {
+		entry, _ := t.history.NthPreviousEntry(n)
+		b.WriteString(entry + "\n")
+	}
+	return os.WriteFile(t.historyConfig.File, []byte(b.String()), 0600)
+}
+
+// addHistory adds a line to the history, following the History settings
+func (t *Tile) addHistory(line string) {
+	h := t.historyConfig
+	if h.IgnoreSpace && strings.HasPrefix(line, " ") {
+		return
+	}
+	if prev, ok := t.history.NthPreviousEntry(0); h.IgnoreDups && ok && prev == line {
+		return
+	}
+	t.history.Add(line)
+	t.SaveHistory() // best effort, SaveHistory reports errors when called directly
+}
+
+// searchLine returns the input line shown during a reverse incremental search
+// and the byte offset of the cursor in it
+func (t *Tile) searchLine() (string, int) {
+	prompt := "(reverse-i-search)`" + t.searchQuery + "': "
+	if t.searchFailed {
+		prompt = "(failed " + prompt[1:]
+	}
+	pos := strings.Index(t.line, t.searchQuery)
+	if pos < 0 || t.searchQuery == "" {
+		pos = len(t.line)
+	}
+	return prompt + t.line, len(prompt) + pos
+}
+
+// searchStart begins a reverse incremental search of the history
+func (t *Tile) searchStart() {
+	t.searching = true
+	t.searchQuery = ""
+	t.searchPending = t.line
+	t.searchFind(0)
+}
+
+// searchFind finds the newest history entry at or before the nth previous entry matching the query
+// and updates the matches for the popup
+func (t *Tile) searchFind(n int) bool {
+	t.searchMatches = t.searchMatches[:0]
+	t.searchIndex = -1
+	t.searchFailed = true
+	found := false
+	for i := 0; i < t.history.Len(); i++ {
+		entry, _ := t.history.NthPreviousEntry(i)
+		if !strings.Contains(entry, t.searchQuery) {
+			continue
+		}
+		if len(t.searchMatches) > 0 && t.searchMatches[len(t.searchMatches)-1] == entry {
+			continue // show repeated entries once
+		}
+		t.searchMatches = append(t.searchMatches, entry)
+		if !found && i >= n {
+			found = true
+			t.searchFailed = false
+			t.searchIndex = len(t.searchMatches) - 1
+			t.searchEntry = i
+			t.setLine(entry)
+		}
+	}
+	return found
+}
+
+// searchEnd ends the search, restoring the line from before the search if cancel is true
+func (t *Tile) searchEnd(cancel bool) {
+	if cancel {
+		t.setLine(t.searchPending)
+	}
+	t.searching = false
+	t.searchMatches = nil
+	t.historyIndex = -1
+}
+
+// searchKey handles a key during a reverse incremental search, returning false if
+// the search ended and the key should also be handled normally
+func (t *Tile) searchKey(r rune) bool {
+	switch r {
+	case CtrlR: // next older match
+		if !t.searchFind(t.searchEntry + 1) {
+			t.searchFind(t.searchEntry)
+		}
+	case CtrlG, KeyEscape:
+		t.searchEnd(true)
+	case KeyBackspace, CtrlH:
+		t.searchQuery = t.searchQuery[:format.PrevGrapheme(t.searchQuery, len(t.searchQuery))]
+		t.searchFind(0)
+	default:
+		if !unicode.IsPrint(r) {
+			t.searchEnd(false)
+			return false
+		}
+		t.searchQuery += string(r)
+		if !t.searchFind(t.searchEntry) {
+			t.searchFind(0)
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package termfun
+
+// lineedit.go is the line editor for tiles with an input line, like TileType_ScrollUp.
+// The edit position linePos is a byte offset in line that is always on a grapheme boundary.
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// editType is the last kind of edit, so kills can merge and yanks can pop
+type editType int
+
+const (
+	edit_Other editType = iota
+	edit_Kill
+	edit_Yank
+)
+
+// killRingSize is the number of kills kept for yanking
+const killRingSize = 10
+
+// lineEditKey handles the line editing keys, returning true if the key was used
+func (t *Tile) lineEditKey(r rune) bool {
+	last := t.lastEdit
+	t.lastEdit = edit_Other
+	switch r {
+	case KeyLeft, CtrlB:
+		t.linePos = format.PrevGrapheme(t.line, t.linePos)
+	case KeyRight, CtrlF:
+		t.linePos = format.NextGrapheme(t.line, t.linePos)
+	case KeyHome, CtrlA:
+		t.linePos = 0
+	case KeyEnd, CtrlE:
+		t.linePos = len(t.line)
+	case KeyWordLeft:
+		t.linePos = wordLeft(t.line, t.linePos)
+	case KeyWordRight:
+		t.linePos = wordRight(t.line, t.linePos)
+	case KeyBackspace, CtrlH:
+		t.lineDelete(format.PrevGrapheme(t.line, t.linePos), t.linePos)
+	case KeyDel, CtrlD:
+		t.lineDelete(t.linePos, format.NextGrapheme(t.line, t.linePos))
+	case CtrlK:
+		t.lineKill(t.linePos, len(t.line), last == edit_Kill)
+	case CtrlU:
+		t.lineKill(0, t.linePos, last == edit_Kill)
+	case CtrlW:
+		t.lineKill(wordLeft(t.line, t.linePos), t.linePos, last == edit_Kill)
+	case CtrlY:
+		t.lineYank(0)
+	case KeyYankPop:
+		if last != edit_Yank {
+			return true
+		}
+		t.lineYank(t.yankIndex + 1)
+	default:
+		if !unicode.IsPrint(r) { // control and special keys
+			t.lastEdit = last
+			return false
+		}
+		t.lineInsert(string(r))
+	}
+	return true
+}
+
+// lineInsert inserts s at the edit position and moves past it
+func (t *Tile) lineInsert(s string) {
+	t.line = t.line[:t.linePos] + s + t.line[t.linePos:]
+	t.linePos += len(s)
+}
+
+// lineDelete deletes line[from:to] and returns it
+func (t *Tile) lineDelete(from, to int) string {
+	if from >= to {
+		return ""
+	}
+	s := t.line[from:to]
+	t.line = t.line[:from] + t.line[to:]
+	if t.linePos >= to {
+		t.linePos -= to - from
+	} else if t.linePos > from {
+		t.linePos = from
+	}
+	return s
+}
+
+// lineKill deletes line[from:to] into the kill ring, merging with the previous kill if merge is true
+func (t *Tile) lineKill(from, to int, merge bool) {
+	backward := from < t.linePos
+	killed := t.lineDelete(from, to)
+	t.lastEdit = edit_Kill
+	if merge && len(t.killRing) > 0 {
+		if backward {
+			t.killRing[0] = killed + t.killRing[0]
+		} else {
+			t.killRing[0] += killed
+		}
+		return
+	}
+	if killed == "" {
+		return
+	}
+	t.killRing = append([]string{killed}, t.killRing...)
+	if len(t.killRing) > killRingSize {
+		t.killRing = t.killRing[:killRingSize]
+	}
+}
+
+// lineYank inserts the nth kill, replacing the previous yank if the last edit was a yank
+func (t *Tile) lineYank(n int) {
+	if len(t.killRing) == 0 {
+		return
+	}
+	if n > 0 { // yank pop replaces the last yank
+		t.lineDelete(t.yankStart, t.linePos)
+	}
+	t.yankIndex = n % len(t.killRing)
+	t.yankStart = t.linePos
+	t.lineInsert(t.killRing[t.yankIndex])
+	t.lastEdit = edit_Yank
+}
+
+// isWordRune returns true for runes that are part of a word for word motions
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// wordLeft returns the start of the word before pos
+func wordLeft(s string, pos int) int {
+	for pos > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:pos])
+		if isWordRune(r) {
+			break
+		}
+		pos -= size
+	}
+	for pos > 0 {
+		r, size := utf8.DecodeLastRuneInString(s[:pos])
+		if !isWordRune(r) && !unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) {
+			break
+		}
+		pos -= size
+	}
+	return pos
+}
+
+// wordRight returns the end of the word after pos
+func wordRight(s string, pos int) int {
+	for pos < len(s) {
+		r, size := utf8.DecodeRuneInString(s[pos:])
+		if isWordRune(r) {
+			break
+		}
+		pos += size
+	}
+	for pos < len(s) {
+		r, size := utf8.DecodeRuneInString(s[pos:])
+		if !isWordRune(r) && !unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) {
+			break
+		}
+		pos += size
+	}
+	return pos
+}
@@ -0,0 +1,251 @@
+package termfun
+
+// list.go implements TileType_List, a selectable list of items, or menu.
+// The highlighted item is shown in reverse video. Typing filters the items,
+// Enter calls the SelectCallback with the selected items.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// ListItem is an item of a TileType_List tile
+// Value is not used by the tile, it is for the caller to identify the item
+type ListItem struct {
+	Label string
+	Value any
+}
+
+// SelectCallback is called on Enter in a TileType_List tile with the indexes of the selected items
+// In single select mode it is the highlighted item, in multi select mode the checked items,
+// or the highlighted item if none are checked. Returning true exits TileTerm.
+type SelectCallback func(selected []int) bool
+
+// listState holds the state of a TileType_List tile
+type listState struct {
+	items    []ListItem
+	multi    bool           // multi select, Space checks items
+	checked  map[int]bool   // checked items in multi select mode
+	filter   string         // type-ahead filter
+	view     []int          // items that match the filter
+	index    int            // highlighted entry in view
+	callback SelectCallback // called on Enter, or nil
+}
+
+// SetItems sets the items of a TileType_List tile, clearing any filter and checked items
+func (t *Tile) SetItems(items []ListItem) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_List {
+		return errors.New("Handler.TileType does not support Items")
+	}
+	t.list.items = items
+	t.list.checked = make(map[int]bool)
+	t.list.filter = ""
+	t.list.index = 0
+	t.listFilter()
+	t.dirty = true
+	return nil
+}
+
+// Items returns the items of a TileType_List tile
+func (t *Tile) Items() []ListItem {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.list.items
+}
+
+// SetMultiSelect turns multi select on or off for a TileType_List tile
+func (t *Tile) SetMultiSelect(multi bool) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_List {
+		return errors.New("Handler.TileType does not support MultiSelect")
+	}
+	t.list.multi = multi
+	t.list.checked = make(map[int]bool)
+	t.dirty = true
+	return nil
+}
+
+// SetSelectCallback sets the Select Callback function for TileType_List
+func (t *Tile) SetSelectCallback(c SelectCallback) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_List {
+		return errors.New("Handler.TileType does not support Callback")
+	}
+	t.list.callback = c
+	return nil
+}
+
+// Selected returns the indexes of the items a SelectCallback would get now
+func (t *Tile) Selected() []int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.listSelected()
+}
+
+// listSelected returns the checked items in order, or the highlighted item
+func (t *Tile) listSelected() []int {
+	l := &t.list
+	var sel []int
+	if l.multi {
+		for i := range l.items {
+			if l.checked[i] {
+				sel = append(sel, i)
+			}
+		}
+	}
+	if len(sel) == 0 && l.index < len(l.view) {
+		sel = []int{l.view[l.index]}
+	}
+	return sel
+}
+
+// listFilter rebuilds the view of items whose label contains the filter, ignoring case,
+// keeping the highlighted item if it still matches
+func (t *Tile) listFilter() {
+	l := &t.list
+	current := -1
+	if l.index < len(l.view) {
+		current = l.view[l.index]
+	}
+	filter := strings.ToLower(l.filter)
+	l.view = l.view[:0]
+	l.index = 0
+	for i, item := range l.items {
+		if strings.Contains(strings.ToLower(item.Label), filter) {
+			if i == current {
+				l.index = len(l.view)
+			}
+			l.view = append(l.view, i)
+		}
+	}
+}
+
+// listStatus returns the filter and position for the bottom outline, or ""
+func (t *Tile) listStatus() string {
+	l := &t.list
+	if t.handler.TileType != TileType_List || len(l.items) == 0 {
+		return ""
+	}
+	pos := fmt.Sprintf("%d/%d", l.index+1, len(l.view))
+	if len(l.view) == 0 {
+		pos = "no match"
+	}
+	if l.filter != "" {
+		return "filter: " + l.filter + " " + pos
+	}
+	return pos
+}
+
+// == TileType_List Handler Functions
+func li_RenderText(t *Tile) string {
+	l := &t.list
+	h := t.Height()
+	// scroll so the highlighted entry is visible
+	if l.index < t.start.Y {
+		t.start.Y = l.index
+	}
+	if l.index >= t.start.Y+h {
+		t.start.Y = l.index - h + 1
+	}
+	if t.start.Y > len(l.view)-h {
+		t.start.Y = len(l.view) - h
+	}
+	if t.start.Y < 0 {
+		t.start.Y = 0
+	}
+	t.lineCount = len(l.view)
+	var str string
+	for row := 0; row < h; row++ {
+		str += CUP(t.bounds.Min.X, t.bounds.Min.Y+row)
+		i := t.start.Y + row
+		if i >= len(l.view) {
+			str += padSpaces(t.Width())
+			continue
+		}
+		label := l.items[l.view[i]].Label
+		if l.multi {
+			if l.checked[l.view[i]] {
+				label = "[x] " + label
+			} else {
+				label = "[ ] " + label
+			}
+		}
+		line := format.FormatTextClipCol(label, t.Width(), 1, 0)[0]
+		if i == l.index {
+			str += SGR(SGR_Negative) + line + SGR(SGR_Off)
+		} else {
+			str += line
+		}
+	}
+	t.curPos = Point{X: t.bounds.Min.X, Y: t.bounds.Min.Y + l.index - t.start.Y}
+	return str
+}
+
+// li_CopyLines returns the labels of the items shown
+func li_CopyLines(t *Tile) []string {
+	var lines []string
+	for _, index := range t.list.view {
+		lines = append(lines, t.list.items[index].Label)
+	}
+	return lines
+}
+
+// li_KeyPress moves the highlight, edits the type-ahead filter and selects
+// KeyUp, KeyDown, KeyPgUp, KeyPgDn, KeyHome, KeyEnd: move the highlight
+// KeySpace: check or uncheck the highlighted item in multi select mode
+// KeyBackspace: delete the last filter character, KeyEscape: clear the filter
+// KeyEnter: call the SelectCallback
+func li_KeyPress(t *Tile, r rune) bool {
+	l := &t.list
+	t.setDirty()
+	switch {
+	case r == KeyUp:
+		l.index--
+	case r == KeyDown:
+		l.index++
+	case r == KeyPgUp:
+		l.index -= t.Height()
+	case r == KeyPgDn:
+		l.index += t.Height()
+	case r == KeyHome:
+		l.index = 0
+	case r == KeyEnd:
+		l.index = len(l.view) - 1
+	case r == KeySpace && l.multi:
+		if l.index < len(l.view) {
+			i := l.view[l.index]
+			l.checked[i] = !l.checked[i]
+		}
+	case r == KeyBackspace:
+		if l.filter != "" {
+			rs := []rune(l.filter)
+			l.filter = string(rs[:len(rs)-1])
+			t.listFilter()
+		}
+	case r == KeyEscape:
+		l.filter = ""
+		t.listFilter()
+	case r == KeyEnter:
+		if sel := t.listSelected(); len(sel) > 0 && l.callback != nil {
+			return l.callback(sel)
+		}
+	case unicode.IsPrint(r):
+		l.filter += string(r)
+		t.listFilter()
+	}
+	if l.index >= len(l.view) {
+		l.index = len(l.view) - 1
+	}
+	if l.index < 0 {
+		l.index = 0
+	}
+	return false
+}
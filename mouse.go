@@ -0,0 +1,77 @@
+package termfun
+
+// mouse.go handles mouse clicks when mouse reporting is on, see TileTerm.SetMouse.
+// A left click focuses the tile under it, and a click on a tab in the top outline of a TileType_Tabs tile shows it.
+
+import "fmt"
+
+// SGR mouse reporting of button presses and releases
+const (
+	mouseOn  = "\x1b[?1000h\x1b[?1006h"
+	mouseOff = "\x1b[?1000l\x1b[?1006l"
+)
+
+// SetMouse turns mouse reporting on or off, it is off by default so the terminal keeps its own text selection
+// It is turned off when Start returns
+func (tTerm *TileTerm) SetMouse(on bool) {
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	tTerm.mouse = on
+	if on {
+		fmt.Fprint(tTerm.out, mouseOn)
+	} else {
+		fmt.Fprint(tTerm.out, mouseOff)
+	}
+}
+
+// mouseOff turns mouse reporting off if it is on
+func (tTerm *TileTerm) mouseOff() {
+	if tTerm.mouse {
+		tTerm.SetMouse(false)
+	}
+}
+
+// handleMouse focuses the tile under a left click, and shows a clicked tab
+func (tTerm *TileTerm) handleMouse(m Mouse) {
+	if m.Button != 0 || m.Release || (tTerm.focus != nil && tTerm.focus.inner().copy.active) {
+		return
+	}
+	tTerm.lock.Lock()
+	tile := tTerm.tileAt(m.X, m.Y)
+	if modal := tTerm.modal(); modal != nil && tile != modal {
+		tile = nil // a modal float keeps the focus
+	}
+	if tile != nil {
+		tTerm.focus = tile
+		tTerm.dirty = true
+	}
+	tTerm.lock.Unlock()
+	if tile == nil {
+		return
+	}
+	if tile.handler.TileType == TileType_Tabs && m.Y == tile.bounds.Min.Y-1 {
+		if i := tile.tabAt(m.X); i >= 0 {
+			tile.SetActiveTab(i)
+		}
+	}
+	tTerm.Render()
+}
+
+// tileAt returns the top tile at x, y including its outline, or nil, status bars are skipped
+func (tTerm *TileTerm) tileAt(x, y int) *Tile {
+	tiles := tTerm.allTiles()
+	for i := len(tiles) - 1; i >= 0; i-- {
+		t := tiles[i]
+		if t.handler.TileType == TileType_StatusBar {
+			continue
+		}
+		r := t.bounds
+		if t.outline != nil {
+			r = IncRect(r)
+		}
+		if x >= r.Min.X && x <= r.Max.X && y >= r.Min.Y && y <= r.Max.Y {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package termfun
+
+// proc.go binds a process to a TileType_ScrollUp tile with Tile.Exec.
+// Output streams into the tile as it arrives, input lines go to the process,
+// and the title shows the running time and then the exit status.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// StderrStyle is the SGR style of stderr output from Tile.Exec
+var StderrStyle = []SGRType{SGR_FgRed}
+
+// procState holds the process started with Tile.Exec
+type procState struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	start   time.Time
+	runtime time.Duration // total run time once done
+	done    bool          // the process exited
+	err     error         // exit error of the process, or nil
+	sent    chan struct{} // closed when the last send to stdin is done, or nil
+}
+
+// Exec starts cmd in a TileType_ScrollUp tile, streaming its stdout and stderr into the tile as they arrive,
+// stderr styled with StderrStyle. While it runs, lines entered in the tile go to its stdin,
+// Ctrl-D on an empty line closes its stdin and Ctrl-C sends SIGINT to it and the processes it started,
+// as cmd runs in its own process group where supported.
+// The title shows the run time, then the exit status once it exits.
+func (t *Tile) Exec(cmd *exec.Cmd) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_ScrollUp {
+		return errors.New("Handler.TileType does not support Exec")
+	}
+	if t.proc.cmd != nil && !t.proc.done {
+		return errors.New("process already running")
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = procWriter{tile: t}
+	cmd.Stderr = procWriter{tile: t, style: SGR(StderrStyle...)}
+	procGroup(cmd)
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+	t.proc = procState{cmd: cmd, stdin: stdin, start: time.Now()}
+	go t.procWait(cmd)
+	t.dirty = true
+	return nil
+}
+
+// Running returns true while a process started with Tile.Exec runs
+func (t *Tile) Running() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.procRunning()
+}
+
+// procRunning returns true while a process runs, the tile must be locked
+func (t *Tile) procRunning() bool {
+	return t.proc.cmd != nil && !t.proc.done
+}
+
+// procWait waits for the process to exit, rendering each second so the run time in the title stays current
+func (t *Tile) procWait(cmd *exec.Cmd) {
+	exited := make(chan error)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			t.setDirty()
+			t.renderLater()
+		case err := <-exited:
+			t.lock.Lock()
+			if t.proc.cmd == cmd {
+				t.proc.done, t.proc.err = true, err
+				t.proc.runtime = time.Since(t.proc.start)
+			}
+			if len(t.buffer.partial) > 0 { // end the last output line so the next prompt starts clean
+				t.scrolled(t.buffer.Append("\n"))
+			}
+			t.dirty = true
+			t.lock.Unlock()
+			t.renderLater()
+			return
+		}
+	}
+}
+
+// procInput sends the keys of a TileType_ScrollUp tile to the running process, returns false if none runs
+func (t *Tile) procInput(r rune) bool {
+	t.lock.Lock()
+	p := t.proc
+	running := t.procRunning()
+	line := t.line
+	t.lock.Unlock()
+	if !running {
+		return false
+	}
+	switch {
+	case r == CtrlC:
+		procInterrupt(p.cmd)
+	case r == CtrlD && line == "":
+		t.lock.Lock()
+		t.procSend(nil)
+		t.lock.Unlock()
+	case r == KeyEnter:
+		t.Print(line + "\n") // echo after any partial output line, like a terminal
+		t.lock.Lock()
+		t.setLine("")
+		t.procSend([]byte(line + "\n"))
+		t.lock.Unlock()
+	default:
+		return false
+	}
+	t.setDirty()
+	return true
+}
+
+// procSend writes data to the stdin of the process after any earlier sends, or closes stdin if data is nil
+// It does not wait, so a process that does not read its stdin can not block the keys, the tile must be locked
+func (t *Tile) procSend(data []byte) {
+	stdin, prev, done := t.proc.stdin, t.proc.sent, make(chan struct{})
+	t.proc.sent = done
+	go func() {
+		defer close(done)
+		if prev != nil {
+			<-prev
+		}
+		if data == nil {
+			stdin.Close()
+			return
+		}
+		stdin.Write(data)
+	}()
+}
+
+// procMode returns the run time or exit status for the title, or ""
+func (t *Tile) procMode() string {
+	t.lock.Lock()
+	p := t.proc // procWait updates it
+	t.lock.Unlock()
+	if p.cmd == nil {
+		return ""
+	}
+	if !p.done {
+		return "running " + time.Since(p.start).Round(time.Second).String()
+	}
+	runtime := p.runtime.Round(time.Millisecond * 100).String()
+	var exit *exec.ExitError
+	switch {
+	case p.err == nil:
+		return "exit 0 " + runtime
+	case errors.As(p.err, &exit) && exit.ExitCode() >= 0:
+		return fmt.Sprintf("exit %d %s", exit.ExitCode(), runtime)
+	}
+	return p.err.Error() + " " + runtime
+}
+
+// procWriter appends process output to the tile, each line styled with style if it is not ""
+type procWriter struct {
+	tile  *Tile
+	style string
+}
+
+// Write to support io.Writer interface
+func (w procWriter) Write(buf []byte) (int, error) {
+	s := string(buf)
+	if w.style != "" {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			if line != "" {
+				lines[i] = w.style + line + SGR(SGR_Off)
+			}
+		}
+		s = strings.Join(lines, "\n")
+	}
+	w.tile.Print(s)
+	w.tile.renderLater()
+	return len(buf), nil
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package termfun
+
+import (
+	"os"
+	"os/exec"
+)
+
+// procGroup does nothing where there are no process groups
+func procGroup(cmd *exec.Cmd) {}
+
+// procInterrupt interrupts the process of cmd, the processes it started are not signaled
+func procInterrupt(cmd *exec.Cmd) error {
+	return cmd.Process.Signal(os.Interrupt)
+}
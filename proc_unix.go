@@ -0,0 +1,21 @@
+//go:build unix
+
+package termfun
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// procGroup makes cmd start in its own process group, so procInterrupt reaches the processes it starts
+func procGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// procInterrupt sends SIGINT to the process group of cmd
+func procInterrupt(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package termfun
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// errNoPTY is returned where there is no pseudo terminal support
+var errNoPTY = errors.New("pty not supported on this platform")
+
+// startPTY is only supported on Linux
+func startPTY(cmd *exec.Cmd, w, h int) (*os.File, error) {
+	return nil, errNoPTY
+}
+
+// setPTYSize is only supported on Linux
+func setPTYSize(master *os.File, w, h int) error {
+	return errNoPTY
+}
@@ -0,0 +1,229 @@
+package termfun
+
+// ptytile.go implements TileType_PTY, a terminal emulator tile running a program on a pseudo terminal.
+// The program output goes through a vt.Screen, keys of the focus tile go to the program,
+// and the pseudo terminal is resized with the tile.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/exyzzy/termfun/vt"
+)
+
+// ptyTerm is the TERM of programs started with StartPTY, if the command does not set one
+const ptyTerm = "TERM=xterm-256color"
+
+// ptyState holds the state of a TileType_PTY tile
+type ptyState struct {
+	screen *vt.Screen
+	master *os.File // pseudo terminal master, or nil before StartPTY
+	cmd    *exec.Cmd
+	done   bool  // the program exited
+	err    error // exit error of the program, or nil
+}
+
+// StartPTY starts cmd on a new pseudo terminal in a TileType_PTY tile, the pty is sized to the tile.
+// The tile shows the program output and sends it the keys it gets while it has focus,
+// except for the TileTerm session keys, see TileTerm.SetKeys.
+// When the program exits, the tile title shows its exit status.
+func (t *Tile) StartPTY(cmd *exec.Cmd) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_PTY {
+		return errors.New("Handler.TileType does not support PTY")
+	}
+	if t.pty.master != nil && !t.pty.done {
+		return errors.New("PTY already started")
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	hasTerm := false
+	for _, e := range cmd.Env {
+		hasTerm = hasTerm || strings.HasPrefix(e, "TERM=")
+	}
+	if !hasTerm {
+		cmd.Env = append(cmd.Env, ptyTerm)
+	}
+	w, h := t.Width(), t.Height()
+	master, err := startPTY(cmd, w, h)
+	if err != nil {
+		return err
+	}
+	screen := vt.New(w, h)
+	screen.SetResponse(master)
+	t.pty = ptyState{screen: screen, master: master, cmd: cmd}
+	go t.ptyRead(t.pty)
+	t.dirty = true
+	return nil
+}
+
+// PTYScreen returns the emulated screen of a TileType_PTY tile, or nil before StartPTY
+func (t *Tile) PTYScreen() *vt.Screen {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.pty.screen
+}
+
+// ptyRead copies the program output to the screen until the program exits
+func (t *Tile) ptyRead(p ptyState) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := p.master.Read(buf)
+		if n > 0 {
+			p.screen.Write(buf[:n])
+			t.setDirty()
+			t.renderLater()
+		}
+		if err != nil { // EIO once the program and its children close the pty
+			break
+		}
+	}
+	err := p.cmd.Wait()
+	p.master.Close()
+	t.lock.Lock()
+	if t.pty.master == p.master {
+		t.pty.done, t.pty.err = true, err
+	}
+	t.dirty = true
+	t.lock.Unlock()
+	t.renderLater()
+}
+
+// ptyFit resizes the screen and pseudo terminal to the tile
+func (t *Tile) ptyFit() {
+	if t.pty.screen == nil {
+		return
+	}
+	w, h := t.Width(), t.Height()
+	if sw, sh := t.pty.screen.Size(); sw == w && sh == h {
+		return
+	}
+	t.pty.screen.Resize(w, h)
+	if !t.pty.done {
+		setPTYSize(t.pty.master, w, h)
+	}
+}
+
+// ptyMode returns the exit status for the title, or ""
+func (t *Tile) ptyMode() string {
+	if t.handler.TileType != TileType_PTY || !t.pty.done {
+		return ""
+	}
+	var exit *exec.ExitError
+	switch {
+	case t.pty.err == nil:
+		return "exit 0"
+	case errors.As(t.pty.err, &exit):
+		return fmt.Sprintf("exit %d", exit.ExitCode())
+	}
+	return t.pty.err.Error()
+}
+
+// ptyStatus returns the title set by the program for the bottom outline, or ""
+func (t *Tile) ptyStatus() string {
+	if t.handler.TileType != TileType_PTY || t.pty.screen == nil {
+		return ""
+	}
+	return t.pty.screen.Title()
+}
+
+// ptyKeys maps special keys to the bytes a terminal sends for them
+var ptyKeys = map[rune]string{
+	KeyEnter:     "\r",
+	KeyBackspace: "\x7f",
+	KeyBackTab:   "\x1b[Z",
+	KeyDel:       "\x1b[3~",
+	KeyInsert:    "\x1b[2~",
+	KeyHome:      "\x1b[H",
+	KeyEnd:       "\x1b[F",
+	KeyPgUp:      "\x1b[5~",
+	KeyPgDn:      "\x1b[6~",
+	KeyWordLeft:  "\x1b[1;5D",
+	KeyWordRight: "\x1b[1;5C",
+	KeyYankPop:   "\x1by",
+}
+
+// ptyKey returns the bytes to send to the program for a key, or nil
+// appCursor selects application cursor keys, ESC O A rather than ESC [ A
+func ptyKey(r rune, appCursor bool) []byte {
+	cursor := map[rune]byte{KeyUp: 'A', KeyDown: 'B', KeyRight: 'C', KeyLeft: 'D'}
+	if c, ok := cursor[r]; ok {
+		if appCursor {
+			return []byte{0x1b, 'O', c}
+		}
+		return []byte{0x1b, '[', c}
+	}
+	if s, ok := ptyKeys[r]; ok {
+		return []byte(s)
+	}
+	if !utf8.ValidRune(r) { // other special keys are in the surrogate area
+		return nil
+	}
+	return []byte(string(r))
+}
+
+// == TileType_PTY Handler Functions
+func pt_RenderText(t *Tile) string {
+	t.lock.Lock()
+	t.ptyFit()
+	screen := t.pty.screen
+	t.lock.Unlock()
+	if screen == nil {
+		sd_SetCurPosOrigin(t)
+		return ClearRect(t.bounds)
+	}
+	var str strings.Builder
+	for y := 0; y < t.Height(); y++ {
+		str.WriteString(CUP(t.bounds.Min.X, t.bounds.Min.Y+y))
+		var attr vt.Attr
+		str.WriteString(SGR(SGR_Off))
+		row := screen.Row(y)
+		for x := 0; x < len(row) && x < t.Width(); x++ {
+			c := row[x]
+			if c.Attr != attr {
+				attr = c.Attr
+				str.WriteString(attr.SGR())
+			}
+			if c.Rune == 0 { // second half of a wide character that was overwritten
+				str.WriteRune(' ')
+				continue
+			}
+			str.WriteRune(c.Rune)
+			if x+1 < len(row) && row[x+1].Rune == 0 { // skip the second half
+				x++
+			}
+		}
+		str.WriteString(SGR(SGR_Off))
+	}
+	x, y, _ := screen.Cursor()
+	t.curPos = Point{X: t.bounds.Min.X + x, Y: t.bounds.Min.Y + y}
+	return str.String()
+}
+
+// pt_CopyLines returns the text of the screen
+func pt_CopyLines(t *Tile) []string {
+	if t.pty.screen == nil {
+		return nil
+	}
+	return strings.Split(t.pty.screen.String(), "\n")
+}
+
+// pt_KeyPress sends the key to the program
+func pt_KeyPress(t *Tile, r rune) bool {
+	t.lock.Lock()
+	p := t.pty
+	t.lock.Unlock()
+	if p.master == nil || p.done {
+		return false
+	}
+	if b := ptyKey(r, p.screen.AppCursor()); b != nil {
+		p.master.Write(b)
+	}
+	return false
+}
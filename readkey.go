@@ -1,6 +1,10 @@
 package termfun
 
-import "bufio"
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
 
 const (
 	CtrlA = 0x01 + iota
@@ -48,58 +52,122 @@ const (
 	KeyRight
 	KeyBackTab
 	KeyDel
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDn
+	KeyInsert
+	KeyWordLeft  // Ctrl-Left or Alt-b
+	KeyWordRight // Ctrl-Right or Alt-f
+	KeyYankPop   // Alt-y
+	KeyMouse     // a mouse event, see ReadKeyMouse
 )
 
+// Mouse is a mouse event from an SGR mouse report, see TileTerm.SetMouse
+// Button: 0 left, 1 middle, 2 right, 64 wheel up, 65 wheel down, plus 32 for motion
+// X, Y: terminal position, from 1,1 at the top left
+// Release: true when the button is released
+type Mouse struct {
+	Button  int
+	X, Y    int
+	Release bool
+}
+
 // ReadKey is a drop-in replacement for bufio.ReadRune but returns common keyboard keypresses that are multi-rune
 // as a single utf-16 surrogate rune per the consts above.
 func ReadKey(reader *bufio.Reader) (r rune, size int, err error) {
-	var keybuf [4]rune
-	var keylen int
-	var bts int
+	r, _, size, err = ReadKeyMouse(reader)
+	return r, size, err
+}
+
+// ReadKeyMouse is ReadKey that also returns the Mouse event when the key is KeyMouse
+func ReadKeyMouse(reader *bufio.Reader) (r rune, m Mouse, size int, err error) {
+	c, n, err := reader.ReadRune()
+	if err != nil || c != KeyEscape {
+		return c, m, n, err
+	}
+	bts := n
+
+	c, n, err = reader.ReadRune()
+	if err != nil {
+		return KeyEscape, m, bts, err
+	}
+	switch c {
+	case KeyLBracket, 'O': // CSI or SS3
+	case 'b':
+		return KeyWordLeft, m, bts + n, nil
+	case 'f':
+		return KeyWordRight, m, bts + n, nil
+	case 'y':
+		return KeyYankPop, m, bts + n, nil
+	default:
+		err := reader.UnreadRune() // not a CSI
+		if err != nil {
+			return c, m, n, err
+		}
+		return KeyEscape, m, bts, nil
+	}
+	bts += n
 
+	// parameters then a final byte
+	var params []rune
 	for {
-		c, n, err := reader.ReadRune()
+		c, n, err = reader.ReadRune()
 		if err != nil {
-			return c, n, err
+			return KeyUnknown, m, bts, err
 		}
 		bts += n
-		keybuf[keylen] = c
-		keylen++
+		if c < 0x20 || c > 0x3f { // the final byte, or not a CSI
+			break
+		}
+		if c >= 0x30 && len(params) < 16 { // drop excess parameters and intermediate bytes
+			params = append(params, c)
+		}
+	}
+	param := string(params)
+	ctrl := strings.HasSuffix(param, ";5")
 
-		if keylen == 1 && keybuf[0] != KeyEscape {
-			return keybuf[0], keylen, nil
+	switch c {
+	case 'A':
+		return KeyUp, m, bts, nil
+	case 'B':
+		return KeyDown, m, bts, nil
+	case 'C':
+		if ctrl {
+			return KeyWordRight, m, bts, nil
 		}
-		if keylen == 2 && keybuf[1] != KeyLBracket {
-			err := reader.UnreadRune() // not a CSI
-			if err != nil {
-				return c, n, err
-			}
-			return keybuf[0], keylen - n, nil
+		return KeyRight, m, bts, nil
+	case 'D':
+		if ctrl {
+			return KeyWordLeft, m, bts, nil
 		}
-		if keylen == 3 {
-			switch keybuf[2] {
-			case 65:
-				return KeyUp, bts, nil
-			case 66:
-				return KeyDown, bts, nil
-			case 67:
-				return KeyRight, bts, nil
-			case 68:
-				return KeyLeft, bts, nil
-			case 90:
-				return KeyBackTab, bts, nil
-			case 51:
-				// nothing
-			default:
-				return KeyUnknown, bts, nil
-			}
+		return KeyLeft, m, bts, nil
+	case 'H':
+		return KeyHome, m, bts, nil
+	case 'F':
+		return KeyEnd, m, bts, nil
+	case 'Z':
+		return KeyBackTab, m, bts, nil
+	case 'M', 'm': // SGR mouse report, ESC [ < button ; x ; y M or m for release
+		if _, err := fmt.Sscanf(param, "<%d;%d;%d", &m.Button, &m.X, &m.Y); err == nil {
+			m.Release = c == 'm'
+			return KeyMouse, m, bts, nil
 		}
-		if keylen == 4 {
-			if keybuf[2] == 51 && keybuf[3] == 126 {
-				return KeyDel, bts, nil
-			} else {
-				return KeyUnknown, bts, nil
-			}
+	case '~':
+		switch param {
+		case "1", "7":
+			return KeyHome, m, bts, nil
+		case "2":
+			return KeyInsert, m, bts, nil
+		case "3":
+			return KeyDel, m, bts, nil
+		case "4", "8":
+			return KeyEnd, m, bts, nil
+		case "5":
+			return KeyPgUp, m, bts, nil
+		case "6":
+			return KeyPgDn, m, bts, nil
 		}
 	}
+	return KeyUnknown, m, bts, nil
 }
@@ -45,3 +45,33 @@ func (s *stRingBuffer) NthPreviousEntry(n int) (value string, ok bool) {
 	}
 	return s.entries[index], true
 }
+
+// The methods below are termfun additions to stRingBuffer
+
+// SetMax sets the maximum number of elements, keeping the most recent ones.
+func (s *stRingBuffer) SetMax(max int) {
+	if max < 1 {
+		max = 1
+	}
+	var keep []string
+	for n := s.size - 1; n >= 0; n-- {
+		if e, ok := s.NthPreviousEntry(n); ok {
+			keep = append(keep, e)
+		}
+	}
+	if len(keep) > max {
+		keep = keep[len(keep)-max:]
+	}
+	s.entries = make([]string, max)
+	s.max = max
+	s.head = 0
+	s.size = 0
+	for _, e := range keep {
+		s.Add(e)
+	}
+}
+
+// Len returns the number of elements in the ring.
+func (s *stRingBuffer) Len() int {
+	return s.size
+}
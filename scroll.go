@@ -0,0 +1,138 @@
+package termfun
+
+// scroll.go supports scrolling keys, offset clamping and the scroll indicator
+// for TileType_ScrollDown and TileType_ScrollDownClip tiles.
+
+import (
+	"fmt"
+)
+
+// scrollable returns true if the Tile is a scrollable tile type
+func (t *Tile) scrollable() bool {
+	return t.handler.TileType == TileType_ScrollDown || t.handler.TileType == TileType_ScrollDownClip
+}
+
+// default half page keys of scrollable tiles, see Tile.SetHalfPageKeys
+const (
+	defaultHalfUp   = 'u'
+	defaultHalfDown = 'd'
+)
+
+// SetHalfPageKeys sets the keys that scroll up, down half a page in scrollable tiles, defaults are u and d
+// Use 0 to disable a key, for instance so a custom handler that uses the scroll keys keeps the letter
+func (t *Tile) SetHalfPageKeys(up, down rune) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.halfUp, t.halfDown = up, down
+}
+
+// scrollKey handles the vertical scroll keys, returning true if the key was used
+// KeyUp, KeyDown: one line, or one page of newspaper columns
+// KeyPgUp, KeyPgDn: one page
+// u, d: half a page, see Tile.SetHalfPageKeys
+// KeyHome, KeyEnd: top, bottom
+func (t *Tile) scrollKey(r rune) bool {
+	page := t.Height()
+	if page < 1 {
+		page = 1
+	}
+	step, half := 1, page/2
+	if t.columns > 1 { // page by a screenful of columns
+		step, half = page, page
+	}
+	if half < 1 {
+		half = 1
+	}
+	switch {
+	case r == KeyUp:
+		t.start.Y -= step
+	case r == KeyDown:
+		t.start.Y += step
+	case r == KeyPgUp:
+		t.start.Y -= page
+	case r == KeyPgDn:
+		t.start.Y += page
+	case r == t.halfUp && r != 0:
+		t.start.Y -= half
+	case r == t.halfDown && r != 0:
+		t.start.Y += half
+	case r == KeyHome:
+		t.start.Y = 0
+	case r == KeyEnd:
+		t.start.Y = t.lineCount
+	default:
+		return false
+	}
+	t.scrollClamp()
+	t.setDirty()
+	return true
+}
+
+// scrollBottom returns the largest start.Y that still fills the view
+func (t *Tile) scrollBottom() int {
+	n := t.lineCount
+	bottom := n - t.Height()
+	if t.columns > 1 && n > 0 { // the last page of columns
+		bottom = n - 1 - (n-1)%t.Height()
+	}
+	if bottom < 0 {
+		bottom = 0
+	}
+	return bottom
+}
+
+// scrollClamp keeps the scroll offsets within the content from the last render
+func (t *Tile) scrollClamp() {
+	if bottom := t.scrollBottom(); t.start.Y > bottom {
+		t.start.Y = bottom
+	}
+	if t.start.Y < 0 {
+		t.start.Y = 0
+	}
+	width := t.Width()
+	if t.textWidth > 0 && t.textWidth < width { // less the line number gutter
+		width = t.textWidth
+	}
+	if right := t.maxCol - width; t.start.X > right {
+		t.start.X = right
+	}
+	if t.start.X < 0 {
+		t.start.X = 0
+	}
+}
+
+// scrollStatus returns the "line N/M" indicator for the bottom outline, or "" if all lines fit
+func (t *Tile) scrollStatus() string {
+	if !t.scrollable() || t.lineCount <= t.Height() {
+		return ""
+	}
+	return fmt.Sprintf("line %d/%d", t.start.Y+1, t.lineCount)
+}
+
+// renderScrollbar renders a scrollbar in the right outline, with a thumb for the visible lines
+func (t *Tile) renderScrollbar() string {
+	if !t.scrollable() || t.outline == nil || t.Height() < 2 {
+		return ""
+	}
+	x := t.bounds.Max.X + 1
+	h := t.Height()
+	str := VLine(x, t.bounds.Min.Y, t.bounds.Max.Y+1, t.outline[Box_Vert])
+	if t.lineCount <= h {
+		return str
+	}
+	size := h * h / t.lineCount
+	if size < 1 {
+		size = 1
+	}
+	pos := 0
+	if bottom := t.scrollBottom(); bottom > 0 {
+		pos = (h - size) * t.start.Y / bottom
+	}
+	if pos > h-size {
+		pos = h - size
+	}
+	return str + VLine(x, t.bounds.Min.Y+pos, t.bounds.Min.Y+pos+size, scrollThumb)
+}
+
+// scrollThumb is the scrollbar thumb character, a full block
+const scrollThumb = 0x2588
@@ -0,0 +1,139 @@
+package termfun
+
+// scrollback.go is the line oriented text store for tile buffers.
+// With a scrollback limit, the oldest complete lines are dropped when the limit is exceeded.
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// lineStore holds text as complete lines and a trailing partial line
+type lineStore struct {
+	lines     []string // complete lines, without "\n", from first
+	first     int      // index of the oldest kept line in lines
+	partial   []byte   // text after the last "\n"
+	bytes     int      // bytes held, counting each "\n"
+	maxLines  int      // max complete lines kept, or 0 for no limit
+	maxBytes  int      // max bytes kept, or 0 for no limit
+	discarded int      // total lines dropped
+	joined    string   // the complete lines joined by String, if joinedOK
+	joinedOK  bool
+}
+
+// Append appends s, returning the complete lines dropped to stay within the limits
+// Only s is split, so a long partial line is not scanned again on each write
+func (ls *lineStore) Append(s string) []string {
+	ls.bytes += len(s)
+	parts := strings.Split(s, "\n")
+	if len(parts) > 1 {
+		ls.lines = append(ls.lines, string(ls.partial)+parts[0])
+		ls.lines = append(ls.lines, parts[1:len(parts)-1]...)
+		ls.partial = ls.partial[:0]
+		ls.joinedOK = false
+	}
+	ls.partial = append(ls.partial, parts[len(parts)-1]...)
+	return ls.trim()
+}
+
+// trim drops the oldest lines beyond the limits and returns them,
+// then cuts the start of a partial line that alone is over maxBytes
+func (ls *lineStore) trim() []string {
+	var dropped []string
+	for ls.Len() > 0 && (ls.maxLines > 0 && ls.Len() > ls.maxLines || ls.maxBytes > 0 && ls.bytes > ls.maxBytes) {
+		line := ls.lines[ls.first]
+		ls.lines[ls.first] = ""
+		ls.first++
+		ls.bytes -= len(line) + 1
+		ls.discarded++
+		dropped = append(dropped, line)
+	}
+	if len(dropped) > 0 {
+		ls.joinedOK = false
+	}
+	if ls.first > len(ls.lines)/2 { // compact
+		ls.lines = append(ls.lines[:0], ls.lines[ls.first:]...)
+		ls.first = 0
+	}
+	if ls.maxBytes > 0 && ls.bytes > ls.maxBytes {
+		cut := ls.bytes - ls.maxBytes
+		for cut < len(ls.partial) && !utf8.RuneStart(ls.partial[cut]) {
+			cut++
+		}
+		ls.partial = append(ls.partial[:0], ls.partial[cut:]...)
+		ls.bytes -= cut
+	}
+	return dropped
+}
+
+// SetLimit sets the max lines and bytes kept, 0 for no limit, returning any lines dropped
+func (ls *lineStore) SetLimit(maxLines, maxBytes int) []string {
+	ls.maxLines, ls.maxBytes = maxLines, maxBytes
+	return ls.trim()
+}
+
+// Len returns the number of complete lines kept
+func (ls *lineStore) Len() int {
+	return len(ls.lines) - ls.first
+}
+
+// String returns the text kept, the complete lines are joined once until they change
+func (ls *lineStore) String() string {
+	if ls.Len() == 0 {
+		return string(ls.partial)
+	}
+	if !ls.joinedOK {
+		ls.joined = strings.Join(ls.lines[ls.first:], "\n") + "\n"
+		ls.joinedOK = true
+	}
+	return ls.joined + string(ls.partial)
+}
+
+// Lines returns the complete lines kept followed by the partial line, without joining them
+func (ls *lineStore) Lines() []string {
+	lines := make([]string, 0, ls.Len()+1)
+	lines = append(lines, ls.lines[ls.first:]...)
+	return append(lines, string(ls.partial))
+}
+
+// Reset empties the store, keeping the limits and the discarded count
+func (ls *lineStore) Reset() {
+	ls.lines = nil
+	ls.first = 0
+	ls.partial = nil
+	ls.bytes = 0
+	ls.joined, ls.joinedOK = "", false
+}
+
+// SetScrollback limits the Tile buffer to maxLines lines and maxBytes bytes, 0 for no limit
+// The oldest lines are dropped when a limit is exceeded, and scroll offsets are adjusted to match
+func (t *Tile) SetScrollback(maxLines, maxBytes int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.scrolled(t.buffer.SetLimit(maxLines, maxBytes))
+	t.dirty = true
+}
+
+// Discarded returns the number of lines dropped from the Tile buffer by the scrollback limit
+func (t *Tile) Discarded() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.buffer.discarded
+}
+
+// scrolled moves the scroll offset up by the rows of the dropped lines, so the view keeps its place
+func (t *Tile) scrolled(dropped []string) {
+	if len(dropped) == 0 {
+		return
+	}
+	rows := len(dropped)
+	if t.handler.TileType == TileType_ScrollDown && t.columns <= 1 && t.Width() > 0 {
+		rows = len(format.FormatTextBreakIndent(strings.Join(dropped, "\n"), t.Width(), t.tabs, t.indent))
+	}
+	t.start.Y -= rows
+	if t.start.Y < 0 {
+		t.start.Y = 0
+	}
+}
@@ -0,0 +1,90 @@
+package termfun
+
+import (
+	"strings"
+	"testing"
+)
+
+// go test -run TestLineStoreLines
+func TestLineStoreLines(t *testing.T) {
+	var ls lineStore
+	ls.SetLimit(3, 0)
+	dropped := ls.Append("one\ntwo\nthree\nfour\nfi")
+	if strings.Join(dropped, ",") != "one" {
+		t.Errorf("dropped expected: one but got: %q", dropped)
+	}
+	ls.Append("ve\nsix\n")
+	if got, want := ls.String(), "four\nfive\nsix\n"; got != want {
+		t.Errorf("text expected: %q but got: %q", want, got)
+	}
+	if ls.discarded != 3 || ls.Len() != 3 {
+		t.Errorf("expected 3 discarded, 3 kept but got: %d, %d", ls.discarded, ls.Len())
+	}
+	if ls.bytes != len(ls.String()) {
+		t.Errorf("bytes expected: %d but got: %d", len(ls.String()), ls.bytes)
+	}
+	lines := ls.Lines()
+	if strings.Join(lines, "\n") != ls.String() {
+		t.Errorf("lines expected: %q but got: %q", ls.String(), lines)
+	}
+	ls.Reset()
+	if ls.String() != "" || ls.discarded != 3 {
+		t.Errorf("reset expected empty with 3 discarded but got: %q, %d", ls.String(), ls.discarded)
+	}
+}
+
+// go test -run TestLineStoreBytes
+func TestLineStoreBytes(t *testing.T) {
+	var ls lineStore
+	ls.Append("aaaa\nbbbb\ncccc\n")
+	dropped := ls.SetLimit(0, 5)
+	if strings.Join(dropped, ",") != "aaaa,bbbb" {
+		t.Errorf("dropped expected: aaaa,bbbb but got: %q", dropped)
+	}
+	if got := ls.String(); got != "cccc\n" || ls.bytes != 5 {
+		t.Errorf("expected: %q, 5 bytes but got: %q, %d", "cccc\n", got, ls.bytes)
+	}
+
+	// a partial line over the limit keeps its end
+	ls.Append(strings.Repeat("x", 8))
+	if got := ls.String(); got != "xxxxx" || ls.bytes != 5 || ls.discarded != 3 {
+		t.Errorf("expected: %q, 5 bytes, 3 discarded but got: %q, %d, %d", "xxxxx", got, ls.bytes, ls.discarded)
+	}
+	// on a rune boundary
+	ls.Append("é€x")
+	if got := ls.String(); got != "€x" || ls.bytes != len(got) {
+		t.Errorf("expected: %q but got: %q, %d bytes", "€x", got, ls.bytes)
+	}
+	for i := 0; i < 1000; i++ {
+		ls.Append("y")
+	}
+	if got := ls.String(); got != "yyyyy" {
+		t.Errorf("expected 5 y but got: %q", got)
+	}
+}
+
+// go test -run TestScrolled
+func TestScrolled(t *testing.T) {
+	tile := &Tile{handler: tileHandler[TileType_ScrollDownClip], bounds: Rect{Min: Point{X: 1, Y: 1}, Max: Point{X: 5, Y: 3}}}
+	tile.SetScrollback(4, 0)
+	tile.Print("1\n2\n3\n4\n")
+	tile.start.Y = 3
+	tile.Print("5\n6\n")
+	if tile.start.Y != 1 || tile.Discarded() != 2 {
+		t.Errorf("expected start 1, 2 discarded but got: %d, %d", tile.start.Y, tile.Discarded())
+	}
+	tile.Print("7\n8\n")
+	if tile.start.Y != 0 {
+		t.Errorf("start expected: 0 but got: %d", tile.start.Y)
+	}
+
+	// a dropped line that breaks into rows of a ScrollDown tile moves the view by its rows
+	tile = &Tile{handler: tileHandler[TileType_ScrollDown], bounds: Rect{Min: Point{X: 1, Y: 1}, Max: Point{X: 5, Y: 3}}}
+	tile.SetScrollback(2, 0)
+	tile.Print("aaaa bbbb cccc\n2\n")
+	tile.start.Y = 4
+	tile.Print("3\n")
+	if tile.start.Y != 1 {
+		t.Errorf("start expected: 1 but got: %d", tile.start.Y)
+	}
+}
@@ -0,0 +1,230 @@
+package termfun
+
+// statusbar.go implements TileType_StatusBar, a one line bar without an outline pinned to the top or bottom
+// of the terminal. It holds left, center and right segments whose text comes from callbacks, called on
+// each render and on a tick, and it never takes the focus.
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// SegmentFunc returns the text of a status bar segment, it is called on each render of the bar
+type SegmentFunc func() string
+
+// segmentGap is the space between the segments of a status bar
+const segmentGap = "  "
+
+// barState holds the state of a TileType_StatusBar tile
+type barState struct {
+	location LocType // Loc_Top or Loc_Bottom of the terminal
+	left     []SegmentFunc
+	center   []SegmentFunc
+	right    []SegmentFunc
+	tick     *time.Ticker // renders the bar to update the segments, or nil
+}
+
+// AddStatusBar adds a one line status bar without an outline, pinned to the top or bottom of the terminal
+// by location, Loc_Top or Loc_Bottom, the tiles are laid out in the rest. Remove it with DeleteTile.
+// The bar is skipped by focus cycling and mouse clicks, see Tile.SetSegments.
+func (tTerm *TileTerm) AddStatusBar(location LocType) (*Tile, error) {
+	if location != Loc_Top && location != Loc_Bottom {
+		return nil, errors.New("status bar location must be Loc_Top or Loc_Bottom")
+	}
+	th, err := getTileHandler(TileType_StatusBar)
+	if err != nil {
+		return nil, err
+	}
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	tile := tTerm.newTile("", "", [6]int{}, th)
+	tile.outline = nil
+	tile.location = location
+	tile.bar.location = location
+	tTerm.bars = append(tTerm.bars, tile)
+	tTerm.dirty = true
+	return tile, nil
+}
+
+// SetSegments sets the left, center and right segments of a TileType_StatusBar tile, each joined with a gap
+// Left segments are kept first, then right ones, the center is shown if there is room
+func (t *Tile) SetSegments(left, center, right []SegmentFunc) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_StatusBar {
+		return errors.New("Handler.TileType does not support Segments")
+	}
+	t.bar.left, t.bar.center, t.bar.right = left, center, right
+	t.dirty = true
+	return nil
+}
+
+// SetTick renders a TileType_StatusBar tile every d to update its segments, such as a Clock, or stops it for 0
+// The tick stops when the bar is deleted
+func (t *Tile) SetTick(d time.Duration) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_StatusBar {
+		return errors.New("Handler.TileType does not support Tick")
+	}
+	if t.bar.tick != nil {
+		t.bar.tick.Stop()
+		t.bar.tick = nil
+	}
+	if d > 0 {
+		t.bar.tick = time.NewTicker(d)
+		go t.barTick(t.bar.tick)
+	}
+	return nil
+}
+
+// barTick renders on each tick until the ticker is stopped or the bar is deleted
+func (t *Tile) barTick(tick *time.Ticker) {
+	for range tick.C {
+		t.lock.Lock()
+		stopped := t.bar.tick != tick
+		t.lock.Unlock()
+		if stopped || t.term == nil || !t.term.hasBar(t) {
+			tick.Stop()
+			return
+		}
+		t.setDirty()
+		t.renderLater()
+	}
+}
+
+// Clock returns a SegmentFunc with the current time in the time.Format layout, like "15:04:05"
+func Clock(layout string) SegmentFunc {
+	return func() string {
+		return time.Now().Format(layout)
+	}
+}
+
+// FocusSegment returns a SegmentFunc with the name of the focus tile, or its active tab
+func (tTerm *TileTerm) FocusSegment() SegmentFunc {
+	return func() string {
+		tTerm.lock.Lock()
+		focus := tTerm.focus
+		tTerm.lock.Unlock()
+		if focus == nil {
+			return ""
+		}
+		return strings.TrimSpace(focus.inner().name)
+	}
+}
+
+// ModeSegment returns a SegmentFunc with the modes of the focus tile: copy, follow, running or exit status
+func (tTerm *TileTerm) ModeSegment() SegmentFunc {
+	return func() string {
+		tTerm.lock.Lock()
+		focus := tTerm.focus
+		tTerm.lock.Unlock()
+		if focus == nil {
+			return ""
+		}
+		inner := focus.inner()
+		var modes []string
+		if inner.copy.active {
+			modes = append(modes, "copy")
+		}
+		if mode := inner.mode(); mode != "" {
+			modes = append(modes, mode)
+		}
+		return strings.Join(modes, " ")
+	}
+}
+
+// hasBar returns true if tile is a current status bar
+func (tTerm *TileTerm) hasBar(tile *Tile) bool {
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	for _, b := range tTerm.bars {
+		if b == tile {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteBar removes a status bar and returns true if it was one
+func (tTerm *TileTerm) deleteBar(tile *Tile) bool {
+	for i, b := range tTerm.bars {
+		if b == tile {
+			tTerm.bars = append(tTerm.bars[:i:i], tTerm.bars[i+1:]...)
+			tTerm.dirty = true
+			return true
+		}
+	}
+	return false
+}
+
+// layoutBars places the status bars on the top and bottom rows of a width x height terminal,
+// in the order they were added, and returns the rect left for the tiles
+func (tTerm *TileTerm) layoutBars(width, height int) Rect {
+	r := Rect{Min: Point{X: 1, Y: 1}, Max: Point{X: width, Y: height}}
+	for _, b := range tTerm.bars {
+		if b.bar.location == Loc_Top {
+			b.bounds = Rect{Min: Point{X: 1, Y: r.Min.Y}, Max: Point{X: width, Y: r.Min.Y}}
+			r.Min.Y++
+		} else {
+			b.bounds = Rect{Min: Point{X: 1, Y: r.Max.Y}, Max: Point{X: width, Y: r.Max.Y}}
+			r.Max.Y--
+		}
+	}
+	return r
+}
+
+// segments returns the text of segments joined with segmentGap, skipping empty ones
+func segments(fs []SegmentFunc) string {
+	var texts []string
+	for _, f := range fs {
+		if text := f(); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, segmentGap)
+}
+
+// barLine returns the left, center and right text as a line of width columns
+func barLine(left, center, right string, width int) string {
+	if left != "" {
+		left = " " + left
+	}
+	if right != "" {
+		right += " "
+	}
+	lw, cw, rw := format.StringWidth(left), format.StringWidth(center), format.StringWidth(right)
+	if lw > width {
+		return format.FormatTextClipCol(left, width, 1, 0)[0]
+	}
+	if lw+rw > width { // no room for the right
+		right, rw = "", 0
+	}
+	cx := (width - cw) / 2
+	if center == "" || cx < lw+1 || cx+cw > width-rw-1 { // no room for the center
+		return left + padSpaces(width-lw-rw) + right
+	}
+	return left + padSpaces(cx-lw) + center + padSpaces(width-rw-cx-cw) + right
+}
+
+// == TileType_StatusBar Handler Functions
+func sb_RenderText(t *Tile) string {
+	t.lock.Lock()
+	bar := t.bar
+	t.lock.Unlock()
+	line := barLine(segments(bar.left), segments(bar.center), segments(bar.right), t.Width())
+	str := CUP(t.bounds.Min.X, t.bounds.Min.Y) + SGR(SGR_Negative) + line + SGR(SGR_Off)
+	for y := t.bounds.Min.Y + 1; y <= t.bounds.Max.Y; y++ { // a status bar added with AddTile
+		str += CUP(t.bounds.Min.X, y) + padSpaces(t.Width())
+	}
+	sd_SetCurPosOrigin(t)
+	return str
+}
+
+// sb_KeyPress ignores keys, a status bar does not take the focus
+func sb_KeyPress(t *Tile, r rune) bool {
+	return false
+}
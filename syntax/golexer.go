@@ -0,0 +1,64 @@
+package syntax
+
+import (
+	"go/scanner"
+	"go/token"
+)
+
+// GoLexer lexes Go source with go/scanner
+var GoLexer Lexer = LexerFunc(lexGo)
+
+// predeclared are the Go predeclared types, constants and functions
+var predeclared = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true, "error": true,
+	"float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+	"int32": true, "int64": true, "rune": true, "string": true, "uint": true,
+	"uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"any": true, "comparable": true, "true": true, "false": true, "iota": true, "nil": true,
+}
+
+func lexGo(src string) []Token {
+	var tokens []Token
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), nil, scanner.ScanComments) // errors are ignored, highlighting is best effort
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		start := file.Offset(pos)
+		end := start + len(lit)
+		if lit == "" {
+			end = start + len(tok.String())
+		}
+		if end > len(src) {
+			end = len(src)
+		}
+		var class Class
+		switch {
+		case tok == token.SEMICOLON && lit == "\n": // automatic semicolon
+			continue
+		case tok == token.COMMENT:
+			class = Class_Comment
+		case tok == token.STRING || tok == token.CHAR:
+			class = Class_String
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = Class_Number
+		case tok.IsKeyword():
+			class = Class_Keyword
+		case tok == token.IDENT:
+			if !predeclared[lit] {
+				continue
+			}
+			class = Class_Type
+		case tok.IsOperator():
+			class = Class_Operator
+		default:
+			continue
+		}
+		tokens = append(tokens, Token{Start: start, End: end, Class: class})
+	}
+	return tokens
+}
@@ -0,0 +1,75 @@
+package syntax
+
+// syntax provides small lexers that classify source text for highlighting.
+// Lexers only find token spans, styling the classes is up to the caller.
+
+// Class is the kind of a token
+type Class int
+
+const (
+	Class_Text Class = iota
+	Class_Keyword
+	Class_Type
+	Class_Ident
+	Class_String
+	Class_Number
+	Class_Comment
+	Class_Operator
+	Class_Key      // JSON and YAML keys
+	Class_Variable // shell variables
+)
+
+// Token is a span of src in byte offsets [Start, End) and its Class
+type Token struct {
+	Start, End int
+	Class      Class
+}
+
+// Lexer splits source text into tokens, in order and not overlapping.
+// Text not covered by a token is Class_Text.
+type Lexer interface {
+	Lex(src string) []Token
+}
+
+// LexerFunc adapts a function to the Lexer interface
+type LexerFunc func(src string) []Token
+
+// Lex calls f(src)
+func (f LexerFunc) Lex(src string) []Token {
+	return f(src)
+}
+
+// lexers holds the registered lexers by name
+var lexers = map[string]Lexer{
+	"go":    GoLexer,
+	"json":  JSONLexer,
+	"yaml":  YAMLLexer,
+	"yml":   YAMLLexer,
+	"sh":    ShellLexer,
+	"bash":  ShellLexer,
+	"shell": ShellLexer,
+}
+
+// Register adds or replaces a lexer by name, such as a file extension without the dot
+func Register(name string, l Lexer) {
+	lexers[name] = l
+}
+
+// ByName returns a registered lexer, or nil if there is none
+func ByName(name string) Lexer {
+	return lexers[name]
+}
+
+// Classes returns the class of each byte of src for the tokens
+func Classes(src string, tokens []Token) []Class {
+	classes := make([]Class, len(src))
+	for _, t := range tokens {
+		if t.Start < 0 || t.End > len(src) {
+			continue
+		}
+		for i := t.Start; i < t.End; i++ {
+			classes[i] = t.Class
+		}
+	}
+	return classes
+}
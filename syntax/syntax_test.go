@@ -0,0 +1,71 @@
+package syntax
+
+import (
+	"testing"
+)
+
+// spans returns the text of each token of class in src
+func spans(src string, tokens []Token, class Class) []string {
+	var out []string
+	for _, t := range tokens {
+		if t.Class == class {
+			out = append(out, src[t.Start:t.End])
+		}
+	}
+	return out
+}
+
+func checkSpans(t *testing.T, name string, got, expected []string) {
+	if len(got) != len(expected) {
+		t.Errorf("%s expected: %q but got: %q", name, expected, got)
+		return
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("%s expected: %q but got: %q", name, expected, got)
+			return
+		}
+	}
+}
+
+// go test -run TestGoLexer
+func TestGoLexer(t *testing.T) {
+	src := "package main\n\n// say hi\nfunc main() {\n\tvar n int = 42\n\tprintln(\"hi\", n)\n}\n"
+	tokens := GoLexer.Lex(src)
+	checkSpans(t, "keywords", spans(src, tokens, Class_Keyword), []string{"package", "func", "var"})
+	checkSpans(t, "types", spans(src, tokens, Class_Type), []string{"int"})
+	checkSpans(t, "comments", spans(src, tokens, Class_Comment), []string{"// say hi"})
+	checkSpans(t, "strings", spans(src, tokens, Class_String), []string{`"hi"`})
+	checkSpans(t, "numbers", spans(src, tokens, Class_Number), []string{"42"})
+}
+
+// go test -run TestJSONLexer
+func TestJSONLexer(t *testing.T) {
+	src := `{"name": "tile", "size": [1, -2.5e3], "ok": true, "x": null}`
+	tokens := JSONLexer.Lex(src)
+	checkSpans(t, "keys", spans(src, tokens, Class_Key), []string{`"name"`, `"size"`, `"ok"`, `"x"`})
+	checkSpans(t, "strings", spans(src, tokens, Class_String), []string{`"tile"`})
+	checkSpans(t, "numbers", spans(src, tokens, Class_Number), []string{"1", "-2.5e3"})
+	checkSpans(t, "keywords", spans(src, tokens, Class_Keyword), []string{"true", "null"})
+}
+
+// go test -run TestYAMLLexer
+func TestYAMLLexer(t *testing.T) {
+	src := "---\nname: tile # the name\nitems:\n  - 42\n  - \"quoted: text\"\nenabled: yes\nurl: http://x\n"
+	tokens := YAMLLexer.Lex(src)
+	checkSpans(t, "keys", spans(src, tokens, Class_Key), []string{"name", "items", "enabled", "url"})
+	checkSpans(t, "comments", spans(src, tokens, Class_Comment), []string{"# the name"})
+	checkSpans(t, "numbers", spans(src, tokens, Class_Number), []string{"42"})
+	checkSpans(t, "strings", spans(src, tokens, Class_String), []string{`"quoted: text"`})
+	checkSpans(t, "keywords", spans(src, tokens, Class_Keyword), []string{"yes"})
+}
+
+// go test -run TestShellLexer
+func TestShellLexer(t *testing.T) {
+	src := "# list\nfor f in *.go; do echo \"$f\" ${HOME} $1 # done\ndone | wc#x\n"
+	tokens := ShellLexer.Lex(src)
+	checkSpans(t, "comments", spans(src, tokens, Class_Comment), []string{"# list", "# done"})
+	checkSpans(t, "keywords", spans(src, tokens, Class_Keyword), []string{"for", "in", "do", "echo", "done"})
+	checkSpans(t, "strings", spans(src, tokens, Class_String), []string{`"$f"`})
+	checkSpans(t, "variables", spans(src, tokens, Class_Variable), []string{"${HOME}", "$1"})
+}
@@ -0,0 +1,265 @@
+package syntax
+
+import (
+	"strings"
+	"unicode"
+)
+
+// JSONLexer lexes JSON, object keys are Class_Key
+var JSONLexer Lexer = LexerFunc(lexJSON)
+
+// YAMLLexer lexes the common line oriented subset of YAML
+var YAMLLexer Lexer = LexerFunc(lexYAML)
+
+// ShellLexer lexes POSIX shell and bash scripts
+var ShellLexer Lexer = LexerFunc(lexShell)
+
+func lexJSON(src string) []Token {
+	var tokens []Token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '"':
+			end := quoteEnd(src, i, '"', true)
+			class := Class_String
+			if j := skipSpace(src, end); j < len(src) && src[j] == ':' {
+				class = Class_Key
+			}
+			tokens = append(tokens, Token{Start: i, End: end, Class: class})
+			i = end
+		case c == '-' || isDigit(c):
+			end := numberEnd(src, i)
+			tokens = append(tokens, Token{Start: i, End: end, Class: Class_Number})
+			i = end
+		case isWordStart(c):
+			end := wordEnd(src, i)
+			switch src[i:end] {
+			case "true", "false", "null":
+				tokens = append(tokens, Token{Start: i, End: end, Class: Class_Keyword})
+			}
+			i = end
+		case strings.IndexByte("{}[]:,", c) >= 0:
+			tokens = append(tokens, Token{Start: i, End: i + 1, Class: Class_Operator})
+			i++
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func lexYAML(src string) []Token {
+	var tokens []Token
+	var offset int
+	for _, line := range strings.SplitAfter(src, "\n") {
+		tokens = append(tokens, lexYAMLLine(line, offset)...)
+		offset += len(line)
+	}
+	return tokens
+}
+
+// lexYAMLLine lexes one line of YAML starting at offset in the source
+func lexYAMLLine(line string, offset int) []Token {
+	var tokens []Token
+	add := func(start, end int, class Class) {
+		tokens = append(tokens, Token{Start: offset + start, End: offset + end, Class: class})
+	}
+	i := skipSpace(line, 0)
+	if strings.HasPrefix(line[i:], "---") || strings.HasPrefix(line[i:], "...") {
+		add(i, i+3, Class_Operator)
+		return tokens
+	}
+	for strings.HasPrefix(line[i:], "- ") { // list items
+		add(i, i+1, Class_Operator)
+		i = skipSpace(line, i+1)
+	}
+	// key: value
+	if key := yamlKeyEnd(line, i); key > i {
+		add(i, key, Class_Key)
+		add(key, key+1, Class_Operator)
+		i = skipSpace(line, key+1)
+	}
+	// value, with an optional trailing comment
+	for i < len(line) {
+		c := line[i]
+		switch {
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			add(i, len(strings.TrimRight(line, "\r\n")), Class_Comment)
+			return tokens
+		case c == '"' || c == '\'':
+			end := quoteEnd(line, i, c, c == '"')
+			add(i, end, Class_String)
+			i = end
+		case c == '-' || isDigit(c):
+			end := numberEnd(line, i)
+			if end > i+1 || isDigit(c) {
+				add(i, end, Class_Number)
+			}
+			i = end
+		case isWordStart(c):
+			end := wordEnd(line, i)
+			switch strings.ToLower(line[i:end]) {
+			case "true", "false", "yes", "no", "on", "off", "null":
+				add(i, end, Class_Keyword)
+			}
+			i = end
+		case c == '&' || c == '*' || c == '!': // anchors, aliases and tags
+			end := i + 1
+			for end < len(line) && !unicode.IsSpace(rune(line[end])) {
+				end++
+			}
+			add(i, end, Class_Type)
+			i = end
+		case c == '|' || c == '>':
+			add(i, i+1, Class_Operator)
+			i++
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// yamlKeyEnd returns the end of a plain or quoted key at i that is followed by ':', or i if none
+func yamlKeyEnd(line string, i int) int {
+	if i >= len(line) {
+		return i
+	}
+	end := i
+	if line[i] == '"' || line[i] == '\'' {
+		end = quoteEnd(line, i, line[i], line[i] == '"')
+	} else {
+		for end < len(line) && line[end] != ':' && line[end] != '#' && line[end] != '\n' {
+			end++
+		}
+	}
+	if end < len(line) && line[end] == ':' && (end+1 == len(line) || unicode.IsSpace(rune(line[end+1]))) {
+		return end
+	}
+	return i
+}
+
+// shellKeywords are the shell reserved words and common builtins
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true, "case": true,
+	"esac": true, "for": true, "while": true, "until": true, "do": true, "done": true,
+	"in": true, "function": true, "select": true, "time": true, "return": true,
+	"export": true, "local": true, "readonly": true, "echo": true, "cd": true,
+	"exit": true, "set": true, "unset": true, "shift": true, "source": true,
+}
+
+func lexShell(src string) []Token {
+	var tokens []Token
+	wordStart := true // at the start of a word, where # begins a comment
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '#' && wordStart:
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src)
+			} else {
+				end += i
+			}
+			tokens = append(tokens, Token{Start: i, End: end, Class: Class_Comment})
+			i = end
+			continue
+		case c == '"' || c == '\'':
+			end := quoteEnd(src, i, c, c == '"')
+			tokens = append(tokens, Token{Start: i, End: end, Class: Class_String})
+			i = end
+		case c == '$':
+			end := i + 1
+			if end < len(src) && src[end] == '{' {
+				if j := strings.IndexByte(src[end:], '}'); j >= 0 {
+					end += j + 1
+				} else {
+					end = len(src)
+				}
+			} else if end < len(src) && strings.IndexByte("?#@*$!0123456789", src[end]) >= 0 {
+				end++
+			} else {
+				end = wordEnd(src, end)
+			}
+			tokens = append(tokens, Token{Start: i, End: end, Class: Class_Variable})
+			i = end
+		case isWordStart(c) && wordStart:
+			end := wordEnd(src, i)
+			if shellKeywords[src[i:end]] && (end == len(src) || strings.IndexByte(" \t\n;&|)", src[end]) >= 0) {
+				tokens = append(tokens, Token{Start: i, End: end, Class: Class_Keyword})
+			}
+			i = end
+		case strings.IndexByte("|&;<>()", c) >= 0:
+			tokens = append(tokens, Token{Start: i, End: i + 1, Class: Class_Operator})
+			i++
+			wordStart = true
+			continue
+		default:
+			i++
+		}
+		wordStart = c == ' ' || c == '\t' || c == '\n'
+	}
+	return tokens
+}
+
+// quoteEnd returns the offset after the closing quote of the string starting at i
+// or the end of the line if it is not closed
+func quoteEnd(s string, i int, quote byte, escapes bool) int {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if escapes {
+				j++
+			}
+		case quote:
+			return j + 1
+		case '\n':
+			return j
+		}
+	}
+	return len(s)
+}
+
+// numberEnd returns the offset after a number starting at i
+func numberEnd(s string, i int) int {
+	j := i
+	if j < len(s) && (s[j] == '-' || s[j] == '+') {
+		j++
+	}
+	hex := strings.HasPrefix(s[j:], "0x") || strings.HasPrefix(s[j:], "0X")
+	if hex {
+		j += 2
+	}
+	for ; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case isDigit(c) || c == '.' || c == '_':
+		case hex && strings.IndexByte("abcdefABCDEF", c) >= 0:
+		case !hex && (c == 'e' || c == 'E'):
+		case !hex && (c == '+' || c == '-') && (s[j-1] == 'e' || s[j-1] == 'E'):
+		default:
+			return j
+		}
+	}
+	return j
+}
+
+// wordEnd returns the offset after a word of letters, digits and '_' starting at i
+func wordEnd(s string, i int) int {
+	for i < len(s) && (isWordStart(s[i]) || isDigit(s[i])) {
+		i++
+	}
+	return i
+}
+
+// skipSpace returns the offset of the first non space or tab at or after i
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isWordStart(c byte) bool { return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
@@ -0,0 +1,223 @@
+package termfun
+
+// tabs.go implements TileType_Tabs, a tile that hosts several child tiles as tabs in its region.
+// The tab names are drawn in the top outline with the active tab highlighted. Only the active child
+// is laid out and rendered, the others keep their buffers and state until they are shown again.
+
+import (
+	"errors"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// default keys of a TileType_Tabs tile to switch to the next, previous tab
+const (
+	defaultTabNext = CtrlN
+	defaultTabPrev = CtrlP
+)
+
+// tabsState holds the state of a TileType_Tabs tile
+type tabsState struct {
+	children []*Tile
+	active   int  // index of the active child
+	next     rune // key to switch to the next tab, or 0
+	prev     rune // key to switch to the previous tab, or 0
+}
+
+// AddTab adds a child tile as the last tab of a TileType_Tabs tile, the first tab added is active
+// The child fills the tab region inside the outline of the tabs tile and has no outline of its own,
+// its name is shown in the tab.
+func (t *Tile) AddTab(name string, cursor string, handler TileType) (*Tile, error) {
+	th, err := getTileHandler(handler)
+	if err != nil {
+		return nil, err
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Tabs || t.term == nil {
+		return nil, errors.New("Handler.TileType does not support Tabs")
+	}
+	child := t.term.newTile(name, cursor, [6]int{}, th)
+	child.outline = t.outline // for the scrollbar, the tabs tile draws the outline
+	child.parent = t
+	child.bounds = t.bounds
+	t.tabbed.children = append(t.tabbed.children, child)
+	t.dirty = true
+	return child, nil
+}
+
+// RemoveTab removes a child tile from a TileType_Tabs tile
+func (t *Tile) RemoveTab(child *Tile) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for i, c := range t.tabbed.children {
+		if c == child {
+			t.tabbed.children = append(t.tabbed.children[:i], t.tabbed.children[i+1:]...)
+			if t.tabbed.active > i || t.tabbed.active >= len(t.tabbed.children) {
+				t.tabbed.active--
+			}
+			t.tabSwitch(t.tabbed.active)
+			return
+		}
+	}
+}
+
+// SetActiveTab shows tab i of a TileType_Tabs tile
+func (t *Tile) SetActiveTab(i int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.tabSwitch(i)
+}
+
+// ActiveTab returns the child tile of the active tab of a TileType_Tabs tile, or nil
+func (t *Tile) ActiveTab() *Tile {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.tabActive()
+}
+
+// SetTabKeys sets the keys of a TileType_Tabs tile that switch to the next, previous tab, defaults are CtrlN and CtrlP
+// Use 0 to disable a key, the keys are not passed to the active child
+func (t *Tile) SetTabKeys(next, prev rune) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Tabs {
+		return errors.New("Handler.TileType does not support Tabs")
+	}
+	t.tabbed.next, t.tabbed.prev = next, prev
+	return nil
+}
+
+// tabActive returns the active child, or nil
+func (t *Tile) tabActive() *Tile {
+	if t.handler.TileType != TileType_Tabs || len(t.tabbed.children) == 0 {
+		return nil
+	}
+	return t.tabbed.children[t.tabbed.active]
+}
+
+// inner returns the active child of a TileType_Tabs tile, or of its active child and so on, otherwise t
+func (t *Tile) inner() *Tile {
+	t.lock.Lock()
+	child := t.tabActive()
+	t.lock.Unlock()
+	if child == nil {
+		return t
+	}
+	return child.inner()
+}
+
+// tabSwitch makes tab i active, wrapping around, and lays it out
+func (t *Tile) tabSwitch(i int) {
+	n := len(t.tabbed.children)
+	if n == 0 {
+		t.tabbed.active = 0
+		return
+	}
+	t.tabbed.active = (i%n + n) % n
+	t.tabFit()
+	t.dirty = true
+}
+
+// tabFit lays out the active child in the tab region
+func (t *Tile) tabFit() {
+	if child := t.tabActive(); child != nil {
+		child.bounds = t.bounds
+		child.laidOut()
+	}
+}
+
+// tabBar returns the tab labels as drawn in the top outline, from its left, and the column of each
+func (t *Tile) tabBar() ([]string, []int) {
+	var labels []string
+	var cols []int
+	col := 1
+	for _, c := range t.tabbed.children {
+		label := " " + c.title() + " "
+		labels = append(labels, label)
+		cols = append(cols, col)
+		col += format.StringWidth(label) + 1
+	}
+	return labels, cols
+}
+
+// tabAt returns the index of the tab drawn at screen column x, or -1
+func (t *Tile) tabAt(x int) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	labels, cols := t.tabBar()
+	for i, label := range labels {
+		x0 := t.bounds.Min.X + cols[i]
+		x1 := x0 + format.StringWidth(label)
+		if x1 > t.bounds.Max.X+1 {
+			break // only tabs that fit are drawn, see renderTabBar
+		}
+		if x >= x0 && x < x1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderTabBar renders the top outline with the tab labels, the active one highlighted
+func (t *Tile) renderTabBar(focus bool) string {
+	y := t.bounds.Min.Y - 1
+	str := HLine(t.bounds.Min.X, t.bounds.Max.X+1, y, t.outline[Box_Horiz])
+	labels, cols := t.tabBar()
+	for i, label := range labels {
+		x := t.bounds.Min.X + cols[i]
+		if x+format.StringWidth(label) > t.bounds.Max.X+1 {
+			break // only tabs that fit
+		}
+		str += CUP(x, y)
+		switch {
+		case i == t.tabbed.active && focus:
+			str += SGR(SGR_Negative, SGR_Bold) + label + SGR(SGR_Off)
+		case i == t.tabbed.active:
+			str += SGR(SGR_Negative) + label + SGR(SGR_Off)
+		default:
+			str += label
+		}
+	}
+	return str
+}
+
+// == TileType_Tabs Handler Functions
+func tb_RenderText(t *Tile) string {
+	t.lock.Lock()
+	t.tabFit()
+	child := t.tabActive()
+	t.lock.Unlock()
+	if child == nil {
+		sd_SetCurPosOrigin(t)
+		return ClearRect(t.bounds)
+	}
+	var str string
+	if child.copy.active {
+		str = child.copyRender()
+	} else {
+		str = child.handler.Render(child)
+	}
+	t.curPos = child.curPos
+	return str
+}
+
+// tb_KeyPress switches tabs with the tab keys, see Tile.SetTabKeys, and passes other keys to the active child
+func tb_KeyPress(t *Tile, r rune) bool {
+	t.lock.Lock()
+	child := t.tabActive()
+	switch {
+	case child == nil:
+	case r == t.tabbed.next && r != 0:
+		t.tabSwitch(t.tabbed.active + 1)
+		child = nil
+	case r == t.tabbed.prev && r != 0:
+		t.tabSwitch(t.tabbed.active - 1)
+		child = nil
+	}
+	t.lock.Unlock()
+	if child == nil {
+		return false
+	}
+	return child.handler.KeyPress(child, r)
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"unicode/utf8"
+
+	"github.com/exyzzy/termfun/format"
 )
 
 type Point struct {
@@ -28,18 +31,37 @@ const (
 // Tile contains the state for a Tile
 type Tile struct {
 	handler      *TileHandler
-	name         string          // tile title
-	bounds       Rect            // tile bounds
-	buffer       strings.Builder // tile text buffer
-	cursor       string          // for tile types that support cursors, or ""
-	outline      *[6]int         // outline / border character set, or nil for no outline
-	curPos       Point           // current position of the tile cursor
-	fraction     float32         // fraction of parent this tile uses
-	location     LocType         // location within parent for this tile
-	parent       *Tile           // parent, or nil if root
-	line         string          // current input line for tiles that use it
-	dirty        bool            // if true re-render tile
-	start        Point           // x,y start of rendering in doc, for scrolling
+	name         string        // tile title
+	bounds       Rect          // tile bounds
+	buffer       lineStore     // tile text buffer
+	cursor       string        // for tile types that support cursors, or ""
+	outline      *[6]int       // outline / border character set, or nil for no outline
+	curPos       Point         // current position of the tile cursor
+	fraction     float32       // fraction of parent this tile uses
+	location     LocType       // location within parent for this tile
+	parent       *Tile         // parent, or nil if root
+	line         string        // current input line for tiles that use it
+	linePos      int           // edit position in line, a byte offset on a grapheme boundary
+	killRing     []string      // killed text for yanking, most recent first
+	lastEdit     editType      // last kind of line edit
+	yankStart    int           // line offset of the last yank
+	yankIndex    int           // killRing index of the last yank
+	dirty        bool          // if true re-render tile
+	start        Point         // x,y start of rendering in doc, for scrolling
+	tabs         format.Tabs   // tab stops used when rendering the buffer
+	indent       format.Indent // continuation line indent for tile types that break lines
+	columns      int           // number of newspaper columns for TileType_ScrollDown, 0 or 1 for none
+	gutter       int           // spaces between newspaper columns
+	highlighter  *Highlighter  // syntax highlighting for TileType_ScrollDownClip, or nil
+	findKey      rune          // key to start a search in scrollable tiles, or 0
+	find         findState     // search state for scrollable tiles
+	follow       bool          // follow mode, stick to the bottom as text arrives
+	following    bool          // following, false if follow mode is paused
+	lineCount    int           // lines in the last render of scrollable tiles
+	maxCol       int           // widest line in the last render of TileType_ScrollDownClip
+	textWidth    int           // text columns in the last render of TileType_ScrollDownClip, less the line number gutter
+	halfUp       rune          // key to scroll up half a page in scrollable tiles, or 0
+	halfDown     rune          // key to scroll down half a page in scrollable tiles, or 0
 	keyCallback  KeyCallback
 	lineCallback LineCallback
 	lock         sync.Mutex
@@ -55,6 +77,59 @@ type Tile struct {
 	// the incomplete, initial line. That value is stored in
 	// historyPending.
 	historyPending string
+	// historyConfig holds the History settings
+	historyConfig History
+	// reverse incremental search state, see Tile.searchKey
+	searching     bool
+	searchQuery   string
+	searchPending string   // line from before the search
+	searchEntry   int      // history entry of the current match
+	searchFailed  bool     // no entry matches the query
+	searchMatches []string // matching entries for the popup, newest first
+	searchIndex   int      // searchMatches index of the current match
+
+	// completion state, see CompletionCallback
+	completionCallback CompletionCallback
+	completions        []string // completion candidates shown in the popup, or nil
+	compIndex          int      // selected completion, or -1
+	compStart          int      // line offset of the text being completed
+
+	// copy mode state, see TileTerm.SetCopyKey
+	copy copyState
+
+	// TileType_List state, see Tile.SetItems
+	list listState
+
+	// TileType_Form state, see Tile.SetFields
+	form formState
+
+	// TileType_Widgets widgets, see Tile.AddWidget
+	widgets []Widget
+
+	// TileType_Canvas state, see Tile.Canvas
+	canvas canvasState
+
+	// TileType_PTY state, see Tile.StartPTY
+	pty ptyState
+
+	// process state of a TileType_ScrollUp tile, see Tile.Exec
+	proc procState
+
+	// TileType_Tabs state, see Tile.AddTab
+	tabbed tabsState
+
+	// TileType_StatusBar state, see TileTerm.AddStatusBar
+	bar barState
+
+	// TileType_Tree state, see Tile.SetRoot
+	tree treeState
+
+	// TileType_Hex state, see Tile.SetReaderAt
+	hex hexState
+
+	float *floatState // placement of a floating tile, or nil, see TileTerm.AddFloatTile
+
+	term *TileTerm // the session of the tile, or nil
 }
 
 // Width returns the Tile's Width
@@ -74,6 +149,54 @@ func (t *Tile) ResetBuffer() {
 	t.buffer.Reset()
 }
 
+// Name returns the Tile's title name
+func (t *Tile) Name() string {
+	return t.name
+}
+
+// Type returns the TileType of the Tile's handler
+func (t *Tile) Type() TileType {
+	return t.handler.TileType
+}
+
+// Bounds returns the Tile's text bounds, inside any outline
+func (t *Tile) Bounds() Rect {
+	return t.bounds
+}
+
+// Buffer returns the Tile's text buffer
+func (t *Tile) Buffer() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.buffer.String()
+}
+
+// Start returns the x,y start of rendering in the buffer, for scrolling
+func (t *Tile) Start() Point {
+	return t.start
+}
+
+// SetStart sets the x,y start of rendering in the buffer, for scrolling
+func (t *Tile) SetStart(p Point) {
+	t.start = p
+	t.setDirty()
+}
+
+// CurPos returns the Tile's cursor position on the screen
+func (t *Tile) CurPos() Point {
+	return t.curPos
+}
+
+// SetCurPos sets the Tile's cursor position on the screen, typically from a Render handler
+func (t *Tile) SetCurPos(p Point) {
+	t.curPos = p
+}
+
+// SetDirty marks the Tile to be rendered on the next Render
+func (t *Tile) SetDirty() {
+	t.setDirty()
+}
+
 // Cursor returns the current Cursor string for the Tile
 func (t *Tile) Cursor() string {
 	return t.cursor
@@ -84,17 +207,65 @@ func (t *Tile) Line() string {
 	return t.line
 }
 
-// setLine sets the current Line string in the Tile
+// SetLine sets the current Line string for the Tile, with the edit position at its end
+func (t *Tile) SetLine(line string) {
+	t.setLine(line)
+	t.setDirty()
+}
+
+// LinePos returns the edit position in the current Line, as a byte offset
+func (t *Tile) LinePos() int {
+	return t.linePos
+}
+
+// setLine sets the current Line string in the Tile, with the edit position at its end
 func (t *Tile) setLine(line string) {
 	t.line = line
+	t.linePos = len(line)
 	return
 }
 
-// SetKeyCallback sets the Key Callback function for TileType_ScrollDownClipRaw
+// SetTabStops sets the tab size and optional custom tab stop columns (0 based, in any order) for the Tile
+// Beyond the last custom stop, tabs advance to the next multiple of size
+func (t *Tile) SetTabStops(size int, stops ...int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.tabs = format.NewTabs(size, stops...)
+	t.dirty = true
+}
+
+// SetIndent sets how continuation lines are indented when TileType_ScrollDown breaks a line
+func (t *Tile) SetIndent(indent format.Indent) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.indent = indent
+	t.dirty = true
+}
+
+// SetColumns flows TileType_ScrollDown text into newspaper columns separated by gutter spaces
+// Scrolling then moves by a screenful of columns instead of by lines
+func (t *Tile) SetColumns(columns, gutter int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.columns = columns
+	t.gutter = gutter
+	t.start.Y = 0
+	t.dirty = true
+}
+
+// SetHighlighter sets syntax highlighting for TileType_ScrollDownClip, or nil for none
+func (t *Tile) SetHighlighter(h *Highlighter) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.highlighter = h
+	t.dirty = true
+}
+
+// SetKeyCallback sets the Key Callback function for TileType_ScrollDownClipRaw and TileType_Canvas
 func (t *Tile) SetKeyCallback(c KeyCallback) error {
 	t.lock.Lock()
 	defer t.lock.Unlock()
-	if t.handler.TileType == TileType_ScrollDownClipRaw {
+	if t.handler.TileType == TileType_ScrollDownClipRaw || t.handler.TileType == TileType_Canvas {
 		t.keyCallback = c
 		return nil
 	} else {
@@ -114,13 +285,25 @@ func (t *Tile) SetLineCallback(c LineCallback) error {
 	}
 }
 
+// SetCompletionCallback sets the tab Completion Callback function for TileType_ScrollUp
+func (t *Tile) SetCompletionCallback(c CompletionCallback) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType == TileType_ScrollUp {
+		t.completionCallback = c
+		return nil
+	} else {
+		return errors.New("Handler.TileType does not support Callback")
+	}
+}
+
 // Write to support io.Writer interface
 func (tile *Tile) Write(buf []byte) (n int, err error) {
 	tile.setDirty()
 	tile.lock.Lock()
 	defer tile.lock.Unlock()
-	n, err = tile.buffer.Write(buf)
-	return
+	tile.scrolled(tile.buffer.Append(string(buf)))
+	return len(buf), nil
 }
 
 // Print text to a tile buffer
@@ -128,7 +311,7 @@ func (tile *Tile) Print(s ...any) {
 	tile.setDirty()
 	tile.lock.Lock()
 	defer tile.lock.Unlock()
-	fmt.Fprint(&tile.buffer, s...)
+	tile.scrolled(tile.buffer.Append(fmt.Sprint(s...)))
 }
 
 // Println text to a tile buffer
@@ -136,7 +319,7 @@ func (tile *Tile) Println(s ...any) {
 	tile.setDirty()
 	tile.lock.Lock()
 	defer tile.lock.Unlock()
-	fmt.Fprintln(&tile.buffer, s...)
+	tile.scrolled(tile.buffer.Append(fmt.Sprintln(s...)))
 }
 
 // Printf text to a tile buffer
@@ -144,7 +327,7 @@ func (tile *Tile) Printf(format string, s ...any) {
 	tile.setDirty()
 	tile.lock.Lock()
 	defer tile.lock.Unlock()
-	fmt.Fprintf(&tile.buffer, format, s...)
+	tile.scrolled(tile.buffer.Append(fmt.Sprintf(format, s...)))
 }
 
 // setDirty sets the tile status to dirty
@@ -154,6 +337,27 @@ func (tile *Tile) setDirty() {
 	tile.dirty = true
 }
 
+// laidOut updates tile types that size their content to the tile, after the tiles are laid out
+func (t *Tile) laidOut() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	switch t.handler.TileType {
+	case TileType_Canvas:
+		t.canvasFit()
+	case TileType_PTY:
+		t.ptyFit()
+	case TileType_Tabs:
+		t.tabFit()
+	}
+}
+
+// renderLater asks the TileTerm of the tile to render soon, for output that arrives in the background
+func (t *Tile) renderLater() {
+	if t.term != nil {
+		t.term.renderLater()
+	}
+}
+
 //===== Render Helper Functions
 
 // renderOutline renders the tile outline
@@ -161,14 +365,109 @@ func (t *Tile) renderOutline(focus bool) string {
 	var str string
 	if t.outline != nil {
 		if focus {
-			str += Box(IncRect(t.bounds), *(t.outline), t.name, SGR_Negative, SGR_Bold)
+			str += Box(IncRect(t.bounds), *(t.outline), t.title(), SGR_Negative, SGR_Bold)
 		} else {
-			str += Box(IncRect(t.bounds), *(t.outline), t.name)
+			str += Box(IncRect(t.bounds), *(t.outline), t.title())
 		}
 	}
 	return str
 }
 
+// title returns the title shown in the top outline of the tile, the name with any mode
+func (t *Tile) title() string {
+	if t.handler.TileType == TileType_Tabs { // the tab bar is the title, see renderTabBar
+		return ""
+	}
+	mode := t.mode()
+	if mode == "" {
+		return t.name
+	}
+	if t.name == "" || strings.HasSuffix(t.name, " ") {
+		return t.name + "[" + mode + "] "
+	}
+	return t.name + " [" + mode + "]"
+}
+
+// mode returns the follow mode, pty exit status or process status of the tile, or ""
+func (t *Tile) mode() string {
+	if mode := t.followMode(); mode != "" {
+		return mode
+	}
+	if mode := t.ptyMode(); mode != "" {
+		return mode
+	}
+	return t.procMode()
+}
+
+// status returns the status text shown in the bottom outline of the tile, or ""
+func (t *Tile) status() string {
+	if child := t.tabActive(); child != nil {
+		return child.status()
+	}
+	if c := t.copyStatus(); c != "" {
+		return c
+	}
+	if l := t.listStatus(); l != "" {
+		return l
+	}
+	if f := t.formStatus(); f != "" {
+		return f
+	}
+	if tr := t.treeStatus(); tr != "" {
+		return tr
+	}
+	if h := t.hexStatus(); h != "" {
+		return h
+	}
+	if p := t.ptyStatus(); p != "" {
+		return p
+	}
+	find, scroll := t.findStatus(), t.scrollStatus()
+	if find != "" && scroll != "" {
+		return find + " " + scroll
+	}
+	return find + scroll
+}
+
+// renderBorder renders the top and bottom outline, without the corners,
+// so the title and status text can change without rendering all tiles
+func (t *Tile) renderBorder(focus bool) string {
+	if t.outline == nil || t.Width() < 3 {
+		return ""
+	}
+	if t.handler.TileType == TileType_Tabs {
+		return t.renderTabBar(focus) + t.renderStatus() + t.inner().renderScrollbar()
+	}
+	c := t.outline[Box_Horiz]
+	var str string
+	if text := t.title(); len(text) > t.Width() {
+		str += HLine(t.bounds.Min.X, t.bounds.Max.X+1, t.bounds.Min.Y-1, c)
+	} else if focus {
+		str += HLineText(t.bounds.Min.X, t.bounds.Max.X+1, t.bounds.Min.Y-1, c, text, SGR_Negative, SGR_Bold)
+	} else {
+		str += HLineText(t.bounds.Min.X, t.bounds.Max.X+1, t.bounds.Min.Y-1, c, text)
+	}
+	return str + t.renderStatus() + t.renderScrollbar()
+}
+
+// renderStatus renders the bottom outline with any status text near the right
+func (t *Tile) renderStatus() string {
+	y := t.bounds.Max.Y + 1
+	c := t.outline[Box_Horiz]
+	text := t.status()
+	if text == "" {
+		return HLine(t.bounds.Min.X, t.bounds.Max.X+1, y, c)
+	}
+	text = " " + text + " "
+	w := utf8.RuneCountInString(text)
+	if w > t.Width()-2 {
+		text = string([]rune(text)[w-(t.Width()-2):])
+		w = t.Width() - 2
+	}
+	right := 1
+	return HLine(t.bounds.Min.X, t.bounds.Max.X-w-right+1, y, c) + text + strings.Repeat(fmt.Sprintf("%c", c), right)
+}
+
 // Clear clears the tile
 func (t *Tile) Clear() string {
 	return ClearRect(t.bounds)
@@ -7,10 +7,15 @@ import (
 	"io"
 	"os"
 	"sync"
+	"time"
 
+	"github.com/exyzzy/termfun/format"
 	"golang.org/x/term"
 )
 
+// defaultTabSize is the tab size for new tiles, see Tile.SetTabStops
+const defaultTabSize = 3
+
 // TileTerm contains the state for a TileTerm session
 type TileTerm struct {
 	width  int           // width of all combined tiles
@@ -18,11 +23,25 @@ type TileTerm struct {
 	big    *Tile         // if not nil then this tile is enlarged
 	focus  *Tile         // this tile has input focus
 	tiles  []*Tile       // all current tiles
+	floats []*Tile       // floating tiles over the tiles, bottom to top of the z-order
+	bars   []*Tile       // status bars pinned to the top or bottom rows, see AddStatusBar
 	dirty  bool          // if true re-render all tiles
 	in     *os.File      // input
 	out    *os.File      // ouput
 	reader *bufio.Reader // input reader
 	lock   sync.Mutex    // protect TileTerm from concurrent processing issues
+
+	keyNext rune // key to cycle focus to the next tile
+	keyBig  rune // key to enlarge the focus tile (toggle)
+	keyQuit rune // key to quit the TileTerm session
+	keyCopy rune // key to enter copy mode in the focus tile
+	mouse   bool // mouse reporting is on, see SetMouse
+
+	yankCallback YankCallback // receives text yanked in copy mode, or nil
+	osc52        bool         // if true copy mode also yanks to the terminal clipboard
+
+	renderLock    sync.Mutex // one Render at a time
+	renderPending bool       // a renderLater is pending
 }
 
 // NewTileTerm returns a new TileTerm session, typically pass in stdin and stdout
@@ -30,7 +49,18 @@ func NewTileTerm(in, out *os.File) *TileTerm {
 	//make a *bufio.reader
 	reader := bufio.NewReader(in)
 
-	return &TileTerm{dirty: true, in: in, reader: reader, out: out}
+	return &TileTerm{dirty: true, in: in, reader: reader, out: out, keyNext: CtrlT, keyBig: CtrlU, keyQuit: CtrlQ, keyCopy: defaultCopyKey}
+}
+
+// SetKeys sets the session keys that are handled before the focus tile, defaults are CtrlT, CtrlU and CtrlQ
+// next: cycle focus to the next tile
+// big: enlarge the focus tile (toggle)
+// quit: quit the TileTerm session
+// Use 0 to disable a key, for instance SetKeys(CtrlT, CtrlO, CtrlQ) frees CtrlU for line editing
+func (tTerm *TileTerm) SetKeys(next, big, quit rune) {
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	tTerm.keyNext, tTerm.keyBig, tTerm.keyQuit = next, big, quit
 }
 
 // AddTile adds a new tile to the TileTerm session
@@ -40,26 +70,55 @@ func NewTileTerm(in, out *os.File) *TileTerm {
 // fraction: % of parent tile to take for this child
 // location: location within parent tile to use for this child
 // parent: which existing tile to use as parent for this child (root is nil)
-// handler: which tile handler set to use
+// handler: which tile handler set to use, built in or from RegisterTileHandler
 func (tTerm *TileTerm) AddTile(name string, cursor string, outline [6]int, fraction float32, location LocType, parent *Tile, handler TileType) (*Tile, error) {
+	th, err := getTileHandler(handler)
+	if err != nil {
+		return nil, err
+	}
+	return tTerm.addTile(name, cursor, outline, fraction, location, parent, th)
+}
+
+// AddTileHandler is AddTile with a TileHandler used directly, without registration
+// The tile keeps a copy of the handler, its TileType is ignored so it never acts as a built in TileType
+func (tTerm *TileTerm) AddTileHandler(name string, cursor string, outline [6]int, fraction float32, location LocType, parent *Tile, handler *TileHandler) (*Tile, error) {
+	if handler == nil || handler.Render == nil || handler.KeyPress == nil {
+		return nil, fmt.Errorf("tile handler needs Render and KeyPress")
+	}
+	direct := *handler
+	direct.TileType = tileTypeDirect
+	return tTerm.addTile(name, cursor, outline, fraction, location, parent, &direct)
+}
+
+// addTile adds a tile using handler, see AddTile
+func (tTerm *TileTerm) addTile(name string, cursor string, outline [6]int, fraction float32, location LocType, parent *Tile, handler *TileHandler) (*Tile, error) {
 	if len(tTerm.tiles) > 0 && parent == nil {
 		return nil, fmt.Errorf("no parent tile")
 	}
 	tTerm.lock.Lock()
 	defer tTerm.lock.Unlock()
 
-	tile := Tile{name: name, cursor: cursor, outline: &outline, fraction: fraction, location: location, parent: parent, handler: tileHandler[handler], historyIndex: -1}
-	tTerm.tiles = append(tTerm.tiles, &tile)
+	tile := tTerm.newTile(name, cursor, outline, handler)
+	tile.fraction, tile.location, tile.parent = fraction, location, parent
+	tTerm.tiles = append(tTerm.tiles, tile)
 	if len(tTerm.tiles) == 1 {
-		tTerm.focus = &tile
+		tTerm.focus = tile
 	}
-	return &tile, nil
+	return tile, nil
+}
+
+// newTile returns a new tile of the session with the default settings
+func (tTerm *TileTerm) newTile(name string, cursor string, outline [6]int, handler *TileHandler) *Tile {
+	return &Tile{term: tTerm, name: name, cursor: cursor, outline: &outline, handler: handler, tabs: format.NewTabs(defaultTabSize), historyIndex: -1, compIndex: -1, findKey: defaultFindKey, halfUp: defaultHalfUp, halfDown: defaultHalfDown, find: findState{index: -1}, tabbed: tabsState{next: defaultTabNext, prev: defaultTabPrev}}
 }
 
 // DeleteTile deletes a tile and all of its children
 func (tTerm *TileTerm) DeleteTile(tile *Tile) {
 	tTerm.lock.Lock()
 	defer tTerm.lock.Unlock()
+	if tTerm.deleteFloat(tile) || tTerm.deleteBar(tile) {
+		return
+	}
 	if tTerm.focus == tile {
 		if tile != tTerm.tiles[0] {
 			tTerm.focus = tTerm.tiles[0]
@@ -113,10 +172,11 @@ func (tTerm *TileTerm) Start() error {
 		return err
 	}
 	tTerm.setSize(w, h)
+	defer tTerm.mouseOff()
 
 	for {
-		// read a key rune from the reader
-		key, _, err := ReadKey(tTerm.reader)
+		// read a key rune, or mouse event, from the reader
+		key, m, _, err := ReadKeyMouse(tTerm.reader)
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -125,7 +185,9 @@ func (tTerm *TileTerm) Start() error {
 		}
 
 		// handle the key
-		if tTerm.handleKey(key) {
+		if key == KeyMouse {
+			tTerm.handleMouse(m)
+		} else if tTerm.handleKey(key) {
 			return nil
 		}
 	}
@@ -135,9 +197,30 @@ func (tTerm *TileTerm) Start() error {
 // Render renders the TileTerm session to Out
 // It is a convenience function for String()
 func (tTerm *TileTerm) Render() {
+	tTerm.renderLock.Lock()
+	defer tTerm.renderLock.Unlock()
 	fmt.Fprint(tTerm.out, CUP(1, 1), tTerm.String())
 }
 
+// renderDelay is the least time between renders for output that arrives in the background
+const renderDelay = time.Second / 30
+
+// renderLater renders soon, once for all requests until then
+func (tTerm *TileTerm) renderLater() {
+	tTerm.lock.Lock()
+	defer tTerm.lock.Unlock()
+	if tTerm.renderPending {
+		return
+	}
+	tTerm.renderPending = true
+	time.AfterFunc(renderDelay, func() {
+		tTerm.lock.Lock()
+		tTerm.renderPending = false
+		tTerm.lock.Unlock()
+		tTerm.Render()
+	})
+}
+
 // deleteTileChildren deletes the child tiles attached to a parent
 func (tTerm *TileTerm) deleteTileChildren(tile *Tile) {
 	for _, v := range tTerm.tiles {
@@ -166,11 +249,18 @@ func (tTerm *TileTerm) setDirty() {
 	tTerm.dirty = true
 }
 
-// nextTile returns the next tile in order of creation, looping back to the first
+// nextTile returns the next tile in order of creation, then the floating tiles, looping back to the first
+// Status bars are skipped
 func (tTerm *TileTerm) nextTile(cur *Tile) (*Tile, error) {
-	for i, t := range tTerm.tiles {
+	var tiles []*Tile
+	for _, t := range tTerm.allTiles() {
+		if t.handler.TileType != TileType_StatusBar {
+			tiles = append(tiles, t)
+		}
+	}
+	for i, t := range tiles {
 		if t == cur {
-			return tTerm.tiles[(i+1)%len(tTerm.tiles)], nil
+			return tiles[(i+1)%len(tiles)], nil
 		}
 	}
 	return nil, errors.New("no tile match")
@@ -182,11 +272,12 @@ func (tTerm *TileTerm) layoutTiles() error {
 	defer tTerm.lock.Unlock()
 	tw := tTerm.width
 	th := tTerm.height
+	root := tTerm.layoutBars(tw, th)
 	for i, w := range tTerm.tiles {
 		fraction := w.fraction
 		var wr Rect
 		if i == 0 {
-			wr = Rect{Min: Point{X: 1, Y: 1}, Max: Point{X: tw, Y: th}}
+			wr = root
 		} else {
 			pr := w.parent.bounds
 			if tTerm.big != nil {
@@ -230,6 +321,12 @@ func (tTerm *TileTerm) layoutTiles() error {
 		}
 		w.bounds = wr
 	}
+	for _, f := range tTerm.floats {
+		f.bounds = f.floatBounds(tw, th)
+	}
+	for _, w := range tTerm.allTiles() {
+		w.laidOut()
+	}
 	return nil
 }
 
@@ -250,13 +347,21 @@ func (tTerm *TileTerm) renderOutline() string {
 	return str
 }
 
-// renderText renders the text of any dirty tile
+// renderText renders the text of any dirty tile, then the floating tiles over them
 func (tTerm *TileTerm) renderText() string {
 	var str string
-	for _, t := range tTerm.tiles {
-		if t.dirty {
+	for _, t := range tTerm.allTiles() {
+		if t.float != nil { // always, the tiles under it may have rendered over it
+			str += t.renderOutline(tTerm.focus == t)
+		} else if !t.dirty {
+			continue
+		}
+		if t.copy.active {
+			str += t.copyRender()
+		} else {
 			str += t.handler.Render(t)
 		}
+		str += t.renderBorder(tTerm.focus == t)
 	}
 	str += tTerm.renderCursor()
 
@@ -267,7 +372,7 @@ func (tTerm *TileTerm) renderText() string {
 func (tTerm *TileTerm) dirtyAllTiles() {
 	tTerm.lock.Lock()
 	defer tTerm.lock.Unlock()
-	for _, t := range tTerm.tiles {
+	for _, t := range tTerm.allTiles() {
 		t.setDirty()
 	}
 }
@@ -276,15 +381,29 @@ func (tTerm *TileTerm) dirtyAllTiles() {
 // continue to call this until it returns true
 func (tTerm *TileTerm) handleKey(key rune) bool {
 	var err error
-	switch key {
-	case CtrlT: // tab focus to next tile
+	switch {
+	case tTerm.focus != nil && tTerm.focus.inner().copy.active && key != tTerm.keyQuit: // copy mode takes all keys
+		if text, done := tTerm.focus.inner().copyKey(key); done && text != "" {
+			tTerm.yank(text)
+		}
+
+	case tTerm.modal() != nil && key == tTerm.keyNext: // a modal float keeps the focus
+
+	case tTerm.focus != nil && tTerm.focus.float != nil && key == tTerm.keyBig: // floats are not enlarged
+
+	case key == 0: // disabled session keys are 0
+		if tTerm.focus.handler.KeyPress(tTerm.focus, key) {
+			return true
+		}
+
+	case key == tTerm.keyNext: // tab focus to next tile
 		tTerm.focus, err = tTerm.nextTile(tTerm.focus)
 		if err != nil {
 			panic(err)
 		}
 		tTerm.setDirty()
 
-	case CtrlU: // enlarge this tile
+	case key == tTerm.keyBig: // enlarge this tile
 		if tTerm.big == nil {
 			tTerm.big = tTerm.focus
 		} else {
@@ -292,9 +411,12 @@ func (tTerm *TileTerm) handleKey(key rune) bool {
 		}
 		tTerm.setDirty()
 
-	case CtrlQ: // quit TileTerm session
+	case key == tTerm.keyQuit: // quit TileTerm session
 		return true
 
+	case key == tTerm.keyCopy: // enter copy mode in the focus tile, or its active tab
+		tTerm.focus.inner().copyStart()
+
 	default: // pass key to tile for handling
 		if tTerm.focus.handler.KeyPress(tTerm.focus, key) {
 			return true
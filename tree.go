@@ -0,0 +1,362 @@
+package termfun
+
+// tree.go implements TileType_Tree, an expandable tree of TreeNodes drawn with guide lines.
+// Children are loaded when a node is first expanded, so large or remote trees stay cheap,
+// and FSNode adapts an fs.FS for directory browsing.
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// TreeNode is a node of a TileType_Tree tile
+// Label: text shown for the node
+// Leaf: true if the node can not have children, it is not expandable
+// Children: returns the children, it is called when the node is first expanded, an error shows in the bottom outline
+type TreeNode interface {
+	Label() string
+	Leaf() bool
+	Children() ([]TreeNode, error)
+}
+
+// TreeCallback is called on Enter in a TileType_Tree tile with the highlighted node and the path to it from the root
+// Returning true exits TileTerm
+type TreeCallback func(node TreeNode, path []TreeNode) bool
+
+// tree expansion markers, before the label of nodes that are not leaves
+const (
+	treeCollapsed = "▸ "
+	treeExpanded  = "▾ "
+)
+
+// treeItem holds a TreeNode with its expansion state
+type treeItem struct {
+	node     TreeNode
+	parent   *treeItem
+	children []*treeItem // loaded children
+	loaded   bool        // Children was called without error
+	expanded bool
+}
+
+// treeState holds the state of a TileType_Tree tile
+type treeState struct {
+	root     *treeItem
+	rows     []*treeItem  // visible items, top to bottom
+	index    int          // highlighted row
+	err      string       // error of the last Children call, or ""
+	callback TreeCallback // called on Enter, or nil
+}
+
+// SetRoot sets the root node of a TileType_Tree tile and expands it
+func (t *Tile) SetRoot(root TreeNode) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Tree {
+		return errors.New("Handler.TileType does not support Root")
+	}
+	t.tree = treeState{root: &treeItem{node: root}, callback: t.tree.callback}
+	t.treeExpand(t.tree.root)
+	t.treeRows()
+	t.start.Y = 0
+	t.dirty = true
+	return nil
+}
+
+// SetTreeCallback sets the Tree Callback function for TileType_Tree
+func (t *Tile) SetTreeCallback(c TreeCallback) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Tree {
+		return errors.New("Handler.TileType does not support Callback")
+	}
+	t.tree.callback = c
+	return nil
+}
+
+// SelectedNode returns the highlighted node of a TileType_Tree tile and the path to it from the root, or nil
+func (t *Tile) SelectedNode() (TreeNode, []TreeNode) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.treeSelected()
+}
+
+// treeSelected returns the highlighted node and its path, or nil
+func (t *Tile) treeSelected() (TreeNode, []TreeNode) {
+	tr := &t.tree
+	if tr.index >= len(tr.rows) {
+		return nil, nil
+	}
+	var p []TreeNode
+	for it := tr.rows[tr.index]; it != nil; it = it.parent {
+		p = append([]TreeNode{it.node}, p...)
+	}
+	return p[len(p)-1], p
+}
+
+// treeExpand loads the children of an item if needed and expands it, returns false if it has none
+func (t *Tile) treeExpand(it *treeItem) bool {
+	if it.node.Leaf() {
+		return false
+	}
+	if !it.loaded {
+		children, err := it.node.Children()
+		if err != nil {
+			t.tree.err = err.Error()
+			return false
+		}
+		t.tree.err = ""
+		it.children = nil
+		for _, c := range children {
+			it.children = append(it.children, &treeItem{node: c, parent: it})
+		}
+		it.loaded = true
+	}
+	it.expanded = true
+	return len(it.children) > 0
+}
+
+// treeRows rebuilds the visible rows, keeping the highlighted item, or its nearest visible parent
+func (t *Tile) treeRows() {
+	tr := &t.tree
+	var current *treeItem
+	if tr.index < len(tr.rows) {
+		current = tr.rows[tr.index]
+	}
+	tr.rows = tr.rows[:0]
+	var walk func(it *treeItem)
+	walk = func(it *treeItem) {
+		tr.rows = append(tr.rows, it)
+		if it.expanded {
+			for _, c := range it.children {
+				walk(c)
+			}
+		}
+	}
+	if tr.root != nil {
+		walk(tr.root)
+	}
+	tr.index = 0
+	for it := current; it != nil; it = it.parent {
+		for i, row := range tr.rows {
+			if row == it {
+				tr.index = i
+				return
+			}
+		}
+	}
+}
+
+// treeLast returns true if an item is the last child of its parent
+func treeLast(it *treeItem) bool {
+	if it.parent == nil {
+		return true
+	}
+	children := it.parent.children
+	return children[len(children)-1] == it
+}
+
+// treeLine returns the text of an item with its guides and expansion marker
+func treeLine(it *treeItem) string {
+	var guides []string
+	if it.parent != nil {
+		if treeLast(it) {
+			guides = append(guides, "└─ ")
+		} else {
+			guides = append(guides, "├─ ")
+		}
+		for p := it.parent; p.parent != nil; p = p.parent {
+			if treeLast(p) {
+				guides = append([]string{"   "}, guides...)
+			} else {
+				guides = append([]string{"│  "}, guides...)
+			}
+		}
+	}
+	marker := ""
+	switch {
+	case it.node.Leaf():
+	case it.expanded:
+		marker = treeExpanded
+	default:
+		marker = treeCollapsed
+	}
+	return strings.Join(guides, "") + marker + it.node.Label()
+}
+
+// treeLines returns the text of the visible rows
+func (t *Tile) treeLines() []string {
+	var lines []string
+	for _, it := range t.tree.rows {
+		lines = append(lines, treeLine(it))
+	}
+	return lines
+}
+
+// treeStatus returns the last error, or the position, for the bottom outline, or ""
+func (t *Tile) treeStatus() string {
+	tr := &t.tree
+	if t.handler.TileType != TileType_Tree || len(tr.rows) == 0 {
+		return ""
+	}
+	if tr.err != "" {
+		return tr.err
+	}
+	return fmt.Sprintf("%d/%d", tr.index+1, len(tr.rows))
+}
+
+// == TileType_Tree Handler Functions
+func tr_RenderText(t *Tile) string {
+	tr := &t.tree
+	h := t.Height()
+	// scroll so the highlighted row is visible
+	if tr.index < t.start.Y {
+		t.start.Y = tr.index
+	}
+	if tr.index >= t.start.Y+h {
+		t.start.Y = tr.index - h + 1
+	}
+	if t.start.Y > len(tr.rows)-h {
+		t.start.Y = len(tr.rows) - h
+	}
+	if t.start.Y < 0 {
+		t.start.Y = 0
+	}
+	t.lineCount = len(tr.rows)
+	lines := t.treeLines()
+	var str string
+	for row := 0; row < h; row++ {
+		str += CUP(t.bounds.Min.X, t.bounds.Min.Y+row)
+		i := t.start.Y + row
+		if i >= len(lines) {
+			str += padSpaces(t.Width())
+			continue
+		}
+		line := format.FormatTextClipCol(lines[i], t.Width(), 1, 0)[0]
+		if i == tr.index {
+			str += SGR(SGR_Negative) + line + SGR(SGR_Off)
+		} else {
+			str += line
+		}
+	}
+	t.curPos = Point{X: t.bounds.Min.X, Y: t.bounds.Min.Y + tr.index - t.start.Y}
+	return str
+}
+
+// tr_CopyLines returns the visible rows with their guides
+func tr_CopyLines(t *Tile) []string {
+	return t.treeLines()
+}
+
+// tr_KeyPress moves the highlight, expands and collapses nodes, and selects
+// KeyUp, KeyDown, KeyPgUp, KeyPgDn, KeyHome, KeyEnd: move the highlight
+// KeyRight: expand the node, or move to its first child if it is expanded
+// KeyLeft: collapse the node, or move to its parent if it is collapsed
+// KeySpace: expand or collapse the node
+// KeyEnter: call the TreeCallback
+func tr_KeyPress(t *Tile, r rune) bool {
+	tr := &t.tree
+	if len(tr.rows) == 0 {
+		return false
+	}
+	t.setDirty()
+	it := tr.rows[tr.index]
+	switch r {
+	case KeyUp:
+		tr.index--
+	case KeyDown:
+		tr.index++
+	case KeyPgUp:
+		tr.index -= t.Height()
+	case KeyPgDn:
+		tr.index += t.Height()
+	case KeyHome:
+		tr.index = 0
+	case KeyEnd:
+		tr.index = len(tr.rows) - 1
+	case KeyRight:
+		if it.expanded && len(it.children) > 0 {
+			tr.index++
+		} else {
+			t.treeExpand(it)
+			t.treeRows()
+		}
+	case KeyLeft:
+		if it.expanded {
+			it.expanded = false
+		} else if it.parent != nil {
+			tr.index = 0
+			for i, row := range tr.rows {
+				if row == it.parent {
+					tr.index = i
+				}
+			}
+		}
+		t.treeRows()
+	case KeySpace:
+		if it.expanded {
+			it.expanded = false
+		} else {
+			t.treeExpand(it)
+		}
+		t.treeRows()
+	case KeyEnter:
+		if node, p := t.treeSelected(); node != nil && tr.callback != nil {
+			return tr.callback(node, p)
+		}
+	}
+	if tr.index >= len(tr.rows) {
+		tr.index = len(tr.rows) - 1
+	}
+	if tr.index < 0 {
+		tr.index = 0
+	}
+	return false
+}
+
+// FSNode is a TreeNode for a file or directory of an fs.FS, directories are listed first, then files, by name
+type FSNode struct {
+	FS   fs.FS
+	Path string // slash separated path in FS, "." for the root
+	Dir  bool
+}
+
+// NewFSTree returns the TreeNode for directory root of fsys, for instance NewFSTree(os.DirFS("/home"), ".")
+func NewFSTree(fsys fs.FS, root string) *FSNode {
+	return &FSNode{FS: fsys, Path: root, Dir: true}
+}
+
+// Label returns the base name of the node, with a "/" suffix for directories
+func (n *FSNode) Label() string {
+	name := path.Base(n.Path)
+	if n.Dir && name != "/" {
+		name += "/"
+	}
+	return name
+}
+
+// Leaf returns true for files
+func (n *FSNode) Leaf() bool {
+	return !n.Dir
+}
+
+// Children returns the entries of a directory, directories first
+func (n *FSNode) Children() ([]TreeNode, error) {
+	entries, err := fs.ReadDir(n.FS, n.Path)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].IsDir() && !entries[j].IsDir()
+	})
+	children := make([]TreeNode, len(entries))
+	for i, e := range entries {
+		children[i] = &FSNode{FS: n.FS, Path: path.Join(n.Path, e.Name()), Dir: e.IsDir()}
+	}
+	return children, nil
+}
@@ -0,0 +1,869 @@
+// Package vt is a VT100/xterm style terminal emulator that keeps a grid of cells.
+// Write the output of a program to a Screen, then read its cells and cursor to render it.
+package vt
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// Color is a cell color, 0 is the default color,
+// 1 to 256 are palette colors 0 to 255, and RGB colors have the RGB flag set
+type Color uint32
+
+// RGB flags a 24 bit color in the low bits of a Color
+const RGB Color = 1 << 24
+
+// Palette returns the Color for palette index n, 0 to 255
+func Palette(n int) Color { return Color(n + 1) }
+
+// params returns the SGR parameters for the color as a foreground, or background
+func (c Color) params(fg bool) string {
+	base := 30
+	if !fg {
+		base = 40
+	}
+	switch {
+	case c == 0:
+		return strconv.Itoa(base + 9)
+	case c&RGB != 0:
+		return fmt.Sprintf("%d;2;%d;%d;%d", base+8, c>>16&0xff, c>>8&0xff, c&0xff)
+	case c <= 8:
+		return strconv.Itoa(base + int(c) - 1)
+	case c <= 16:
+		return strconv.Itoa(base + 60 + int(c) - 9)
+	}
+	return fmt.Sprintf("%d;5;%d", base+8, c-1)
+}
+
+// Attr holds the rendition of a cell
+type Attr struct {
+	Fg, Bg    Color
+	Bold      bool
+	Faint     bool
+	Italic    bool
+	Underline bool
+	Blink     bool
+	Reverse   bool
+	Hidden    bool
+	Strike    bool
+}
+
+// SGR returns the SGR sequence that sets exactly this rendition
+func (a Attr) SGR() string {
+	p := []string{"0"}
+	for _, f := range []struct {
+		on bool
+		n  string
+	}{{a.Bold, "1"}, {a.Faint, "2"}, {a.Italic, "3"}, {a.Underline, "4"}, {a.Blink, "5"}, {a.Reverse, "7"}, {a.Hidden, "8"}, {a.Strike, "9"}} {
+		if f.on {
+			p = append(p, f.n)
+		}
+	}
+	if a.Fg != 0 {
+		p = append(p, a.Fg.params(true))
+	}
+	if a.Bg != 0 {
+		p = append(p, a.Bg.params(false))
+	}
+	return "\x1b[" + strings.Join(p, ";") + "m"
+}
+
+// Cell is a character position on the Screen, Rune is 0 in the second cell of a wide character
+type Cell struct {
+	Rune rune
+	Attr Attr
+}
+
+// blank is an empty cell
+var blank = Cell{Rune: ' '}
+
+// parser states
+const (
+	stGround = iota
+	stEscape
+	stCharset // ESC ( and friends, one more byte
+	stCSI
+	stOSC
+	stOSCEsc // ESC in an OSC, expecting \
+)
+
+// cursor holds the cursor state saved by DECSC
+type cursor struct {
+	x, y     int
+	attr     Attr
+	graphics bool
+}
+
+// Screen is an emulated terminal screen
+type Screen struct {
+	w, h       int
+	cells      [][]Cell
+	main       [][]Cell // main screen while the alternate screen is in use, or nil
+	cur        cursor
+	saved      cursor
+	mainSaved  cursor // saved cursor of the main screen, for mode 1049
+	wrapNext   bool   // the last column was written, wrap before the next character
+	top, bot   int    // scroll region rows, inclusive
+	autowrap   bool
+	appCursor  bool
+	hidden     bool // cursor hidden
+	insert     bool
+	origin     bool
+	state      int
+	params     []byte // CSI parameter and intermediate bytes
+	osc        []byte
+	utf        []byte // partial UTF-8 sequence
+	g0Graphics bool   // G0 is the DEC special graphics set
+	title      string
+	response   io.Writer
+	lock       sync.Mutex
+}
+
+// New returns a new Screen w columns by h rows
+func New(w, h int) *Screen {
+	s := &Screen{}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.w, s.h = maxInt(w, 1), maxInt(h, 1)
+	s.cells = newGrid(s.w, s.h)
+	s.reset()
+	return s
+}
+
+// newGrid returns a blank grid
+func newGrid(w, h int) [][]Cell {
+	g := make([][]Cell, h)
+	for y := range g {
+		g[y] = make([]Cell, w)
+		for x := range g[y] {
+			g[y][x] = blank
+		}
+	}
+	return g
+}
+
+// maxInt, minInt return the larger, smaller of a and b
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// reset sets the modes to their defaults
+func (s *Screen) reset() {
+	s.cur, s.saved = cursor{}, cursor{}
+	s.top, s.bot = 0, s.h-1
+	s.autowrap = true
+	s.appCursor, s.hidden, s.insert, s.origin = false, false, false, false
+	s.wrapNext = false
+	s.g0Graphics = false
+	s.state = stGround
+}
+
+// SetResponse sets where replies to status requests, like the cursor position, are written, typically the pty
+func (s *Screen) SetResponse(w io.Writer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.response = w
+}
+
+// Size returns the Screen size in columns and rows
+func (s *Screen) Size() (int, int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.w, s.h
+}
+
+// Resize changes the Screen size, keeping the top left of the content and the cursor row
+func (s *Screen) Resize(w, h int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	w, h = maxInt(w, 1), maxInt(h, 1)
+	if w == s.w && h == s.h {
+		return
+	}
+	shift := 0 // keep the cursor row, dropping rows from the top
+	if s.cur.y >= h {
+		shift = s.cur.y - h + 1
+	}
+	resize := func(g [][]Cell) [][]Cell {
+		if g == nil {
+			return nil
+		}
+		n := newGrid(w, h)
+		for y := range n {
+			if y+shift < len(g) {
+				copy(n[y], g[y+shift])
+			}
+		}
+		return n
+	}
+	s.cells = resize(s.cells)
+	s.main = resize(s.main)
+	s.cur.y -= shift
+	s.w, s.h = w, h
+	s.cur.x = minInt(s.cur.x, w-1)
+	s.top, s.bot = 0, h-1
+	s.wrapNext = false
+}
+
+// Cell returns the cell at column x, row y
+func (s *Screen) Cell(x, y int) Cell {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if x < 0 || y < 0 || x >= s.w || y >= s.h {
+		return blank
+	}
+	return s.cells[y][x]
+}
+
+// Row returns a copy of the cells of row y
+func (s *Screen) Row(y int) []Cell {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if y < 0 || y >= s.h {
+		return nil
+	}
+	return append([]Cell(nil), s.cells[y]...)
+}
+
+// Line returns the text of row y without trailing spaces
+func (s *Screen) Line(y int) string {
+	var b strings.Builder
+	for _, c := range s.Row(y) {
+		if c.Rune != 0 {
+			b.WriteRune(c.Rune)
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// String returns the text of the Screen, one line per row
+func (s *Screen) String() string {
+	_, h := s.Size()
+	lines := make([]string, h)
+	for y := range lines {
+		lines[y] = s.Line(y)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Cursor returns the cursor column and row, and true if it is visible
+func (s *Screen) Cursor() (int, int, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.cur.x, s.cur.y, !s.hidden
+}
+
+// AppCursor returns true if the program asked for application cursor keys, ESC O A rather than ESC [ A
+func (s *Screen) AppCursor() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.appCursor
+}
+
+// Title returns the window title set by the program, or ""
+func (s *Screen) Title() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.title
+}
+
+// Write feeds program output to the Screen, it never fails
+func (s *Screen) Write(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, b := range p {
+		s.byte(b)
+	}
+	return len(p), nil
+}
+
+// byte processes one byte of output
+func (s *Screen) byte(b byte) {
+	switch s.state {
+	case stEscape:
+		s.escape(b)
+		return
+	case stCharset:
+		s.state = stGround
+		if s.params[0] == '(' {
+			s.g0Graphics = b == '0'
+		}
+		return
+	case stCSI:
+		if b >= 0x40 && b <= 0x7e {
+			s.state = stGround
+			s.csi(b)
+		} else if b >= 0x20 {
+			s.params = append(s.params, b)
+		} else {
+			s.control(b) // controls are executed inside CSI
+		}
+		return
+	case stOSC:
+		switch b {
+		case 0x07:
+			s.state = stGround
+			s.oscEnd()
+		case 0x1b:
+			s.state = stOSCEsc
+		default:
+			s.osc = append(s.osc, b)
+		}
+		return
+	case stOSCEsc:
+		s.state = stGround
+		s.oscEnd()
+		if b != '\\' {
+			s.byte(b)
+		}
+		return
+	}
+	if len(s.utf) > 0 || b >= 0x80 {
+		s.utf = append(s.utf, b)
+		if utf8.FullRune(s.utf) {
+			r, _ := utf8.DecodeRune(s.utf)
+			s.utf = s.utf[:0]
+			s.print(r)
+		}
+		return
+	}
+	if b < 0x20 || b == 0x7f {
+		s.control(b)
+		return
+	}
+	s.print(rune(b))
+}
+
+// control executes a C0 control
+func (s *Screen) control(b byte) {
+	switch b {
+	case 0x08: // BS
+		if s.cur.x > 0 {
+			s.cur.x--
+		}
+		s.wrapNext = false
+	case 0x09: // HT, tab stops every 8 columns
+		s.cur.x = minInt((s.cur.x/8+1)*8, s.w-1)
+		s.wrapNext = false
+	case 0x0a, 0x0b, 0x0c: // LF, VT, FF
+		s.index()
+	case 0x0d: // CR
+		s.cur.x = 0
+		s.wrapNext = false
+	case 0x0e, 0x0f: // SO, SI
+	case 0x1b:
+		s.state = stEscape
+	}
+}
+
+// graphics maps the DEC special graphics set to box drawing characters
+var graphics = map[rune]rune{
+	'`': '◆', 'a': '▒', 'f': '°', 'g': '±', 'j': '┘', 'k': '┐', 'l': '┌', 'm': '└', 'n': '┼',
+	'q': '─', 't': '├', 'u': '┤', 'v': '┴', 'w': '┬', 'x': '│', 'y': '≤', 'z': '≥', '~': '·',
+}
+
+// print puts a character at the cursor and advances it
+// A wide character takes two cells, the second holds rune 0
+func (s *Screen) print(r rune) {
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) { // combining marks are dropped
+		return
+	}
+	if s.g0Graphics {
+		if g, ok := graphics[r]; ok {
+			r = g
+		}
+	}
+	w := 1
+	if format.GraphemeWidth(string(r)) == 2 && s.w > 1 {
+		w = 2
+	}
+	if s.wrapNext && s.autowrap {
+		s.cur.x = 0
+		s.index()
+	} else if s.cur.x+w > s.w { // no room for a wide character
+		if s.autowrap {
+			s.cells[s.cur.y][s.cur.x] = s.blankCell()
+			s.cur.x = 0
+			s.index()
+		} else {
+			s.cur.x = s.w - w
+		}
+	}
+	s.wrapNext = false
+	row := s.cells[s.cur.y]
+	if s.insert {
+		copy(row[s.cur.x+w:], row[s.cur.x:])
+	}
+	row[s.cur.x] = Cell{Rune: r, Attr: s.cur.attr}
+	if w == 2 {
+		row[s.cur.x+1] = Cell{Attr: s.cur.attr}
+	}
+	if s.cur.x+w < s.w {
+		s.cur.x += w
+	} else {
+		s.cur.x = s.w - 1
+		s.wrapNext = true
+	}
+}
+
+// index moves the cursor down, scrolling at the bottom of the scroll region
+func (s *Screen) index() {
+	s.wrapNext = false
+	if s.cur.y == s.bot {
+		s.scrollUp(s.top, s.bot, 1)
+	} else if s.cur.y < s.h-1 {
+		s.cur.y++
+	}
+}
+
+// reverseIndex moves the cursor up, scrolling at the top of the scroll region
+func (s *Screen) reverseIndex() {
+	s.wrapNext = false
+	if s.cur.y == s.top {
+		s.scrollDown(s.top, s.bot, 1)
+	} else if s.cur.y > 0 {
+		s.cur.y--
+	}
+}
+
+// blankRow returns a blank row in the current background
+func (s *Screen) blankRow() []Cell {
+	row := make([]Cell, s.w)
+	for x := range row {
+		row[x] = s.blankCell()
+	}
+	return row
+}
+
+// blankCell returns a blank cell in the current background, as erase does
+func (s *Screen) blankCell() Cell {
+	return Cell{Rune: ' ', Attr: Attr{Bg: s.cur.attr.Bg}}
+}
+
+// scrollUp scrolls rows top to bot up n rows
+func (s *Screen) scrollUp(top, bot, n int) {
+	n = minInt(n, bot-top+1)
+	copy(s.cells[top:bot+1], s.cells[top+n:bot+1])
+	for y := bot - n + 1; y <= bot; y++ {
+		s.cells[y] = s.blankRow()
+	}
+}
+
+// scrollDown scrolls rows top to bot down n rows
+func (s *Screen) scrollDown(top, bot, n int) {
+	n = minInt(n, bot-top+1)
+	copy(s.cells[top+n:bot+1], s.cells[top:bot+1-n])
+	for y := top; y < top+n; y++ {
+		s.cells[y] = s.blankRow()
+	}
+}
+
+// escape handles the byte after ESC
+func (s *Screen) escape(b byte) {
+	s.state = stGround
+	switch b {
+	case '[':
+		s.state = stCSI
+		s.params = s.params[:0]
+	case ']':
+		s.state = stOSC
+		s.osc = s.osc[:0]
+	case '(', ')', '*', '+', '#', '%':
+		s.state = stCharset
+		s.params = append(s.params[:0], b)
+	case '7': // DECSC
+		s.saved = cursor{x: s.cur.x, y: s.cur.y, attr: s.cur.attr, graphics: s.g0Graphics}
+	case '8': // DECRC
+		s.restoreCursor()
+	case 'D': // IND
+		s.index()
+	case 'E': // NEL
+		s.cur.x = 0
+		s.index()
+	case 'M': // RI
+		s.reverseIndex()
+	case 'c': // RIS
+		s.cells = newGrid(s.w, s.h)
+		s.main = nil
+		s.cur.attr = Attr{}
+		s.reset()
+	}
+}
+
+// restoreCursor restores the cursor saved by DECSC
+func (s *Screen) restoreCursor() {
+	s.cur = s.saved
+	s.cur.x, s.cur.y = minInt(s.cur.x, s.w-1), minInt(s.cur.y, s.h-1)
+	s.g0Graphics = s.saved.graphics
+	s.wrapNext = false
+}
+
+// oscEnd handles an operating system command, only the title is used
+func (s *Screen) oscEnd() {
+	cmd, text, ok := strings.Cut(string(s.osc), ";")
+	if ok && (cmd == "0" || cmd == "2") {
+		s.title = text
+	}
+}
+
+// csiParams returns the numeric parameters of a CSI sequence, the private marker and intermediates
+func (s *Screen) csiParams() ([]int, byte, string) {
+	p := string(s.params)
+	var private byte
+	if p != "" && p[0] >= '<' && p[0] <= '?' {
+		private, p = p[0], p[1:]
+	}
+	i := strings.IndexFunc(p, func(r rune) bool { return r < '0' || r > ';' })
+	var inter string
+	if i >= 0 {
+		p, inter = p[:i], p[i:]
+	}
+	var nums []int
+	if p != "" {
+		for _, f := range strings.FieldsFunc(p, func(r rune) bool { return r == ';' || r == ':' }) {
+			n, _ := strconv.Atoi(f)
+			nums = append(nums, n)
+		}
+	}
+	return nums, private, inter
+}
+
+// arg returns parameter i, or def if it is missing or 0
+func arg(p []int, i, def int) int {
+	if i < len(p) && p[i] > 0 {
+		return p[i]
+	}
+	return def
+}
+
+// csi executes a CSI sequence ending in final
+func (s *Screen) csi(final byte) {
+	p, private, inter := s.csiParams()
+	if inter != "" { // like DECSCUSR, not used
+		return
+	}
+	n := arg(p, 0, 1)
+	s.wrapNext = false
+	switch final {
+	case '@': // ICH
+		row := s.cells[s.cur.y]
+		n = minInt(n, s.w-s.cur.x)
+		copy(row[s.cur.x+n:], row[s.cur.x:])
+		for x := s.cur.x; x < s.cur.x+n; x++ {
+			row[x] = s.blankCell()
+		}
+	case 'A': // CUU
+		s.cur.y = maxInt(s.cur.y-n, s.limitTop())
+	case 'B', 'e': // CUD, VPR
+		s.cur.y = minInt(s.cur.y+n, s.limitBot())
+	case 'C', 'a': // CUF, HPR
+		s.cur.x = minInt(s.cur.x+n, s.w-1)
+	case 'D': // CUB
+		s.cur.x = maxInt(s.cur.x-n, 0)
+	case 'E': // CNL
+		s.cur.y = minInt(s.cur.y+n, s.limitBot())
+		s.cur.x = 0
+	case 'F': // CPL
+		s.cur.y = maxInt(s.cur.y-n, s.limitTop())
+		s.cur.x = 0
+	case 'G', '`': // CHA, HPA
+		s.cur.x = minInt(n-1, s.w-1)
+	case 'H', 'f': // CUP, HVP
+		s.moveTo(arg(p, 1, 1)-1, n-1)
+	case 'd': // VPA
+		s.moveTo(s.cur.x, n-1)
+	case 'J': // ED
+		s.eraseDisplay(arg(p, 0, 0))
+	case 'K': // EL
+		s.eraseLine(arg(p, 0, 0))
+	case 'L': // IL
+		if s.cur.y >= s.top && s.cur.y <= s.bot {
+			s.scrollDown(s.cur.y, s.bot, n)
+			s.cur.x = 0
+		}
+	case 'M': // DL
+		if s.cur.y >= s.top && s.cur.y <= s.bot {
+			s.scrollUp(s.cur.y, s.bot, n)
+			s.cur.x = 0
+		}
+	case 'P': // DCH
+		row := s.cells[s.cur.y]
+		n = minInt(n, s.w-s.cur.x)
+		copy(row[s.cur.x:], row[s.cur.x+n:])
+		for x := s.w - n; x < s.w; x++ {
+			row[x] = s.blankCell()
+		}
+	case 'S': // SU
+		s.scrollUp(s.top, s.bot, n)
+	case 'T': // SD
+		if len(p) < 2 {
+			s.scrollDown(s.top, s.bot, n)
+		}
+	case 'X': // ECH
+		row := s.cells[s.cur.y]
+		for x := s.cur.x; x < minInt(s.cur.x+n, s.w); x++ {
+			row[x] = s.blankCell()
+		}
+	case 'c': // DA
+		if private == 0 {
+			s.respond("\x1b[?6c")
+		}
+	case 'h', 'l':
+		s.setModes(p, private, final == 'h')
+	case 'm':
+		if private == 0 {
+			s.sgr(p)
+		}
+	case 'n': // DSR
+		switch arg(p, 0, 0) {
+		case 5:
+			s.respond("\x1b[0n")
+		case 6:
+			y := s.cur.y
+			if s.origin {
+				y -= s.top
+			}
+			s.respond(fmt.Sprintf("\x1b[%d;%dR", y+1, s.cur.x+1))
+		}
+	case 'r': // DECSTBM
+		if private != 0 {
+			return
+		}
+		top, bot := arg(p, 0, 1)-1, arg(p, 1, s.h)-1
+		if bot >= s.h {
+			bot = s.h - 1
+		}
+		if top < bot {
+			s.top, s.bot = top, bot
+			s.moveTo(0, 0)
+		}
+	case 's': // SCOSC
+		s.saved = cursor{x: s.cur.x, y: s.cur.y, attr: s.cur.attr, graphics: s.g0Graphics}
+	case 'u': // SCORC
+		s.restoreCursor()
+	}
+}
+
+// limitTop, limitBot return the rows the cursor keys stop at
+func (s *Screen) limitTop() int {
+	if s.cur.y >= s.top {
+		return s.top
+	}
+	return 0
+}
+
+func (s *Screen) limitBot() int {
+	if s.cur.y <= s.bot {
+		return s.bot
+	}
+	return s.h - 1
+}
+
+// moveTo moves the cursor to column x, row y, relative to the scroll region in origin mode
+func (s *Screen) moveTo(x, y int) {
+	if s.origin {
+		y += s.top
+		s.cur.y = maxInt(minInt(y, s.bot), s.top)
+	} else {
+		s.cur.y = maxInt(minInt(y, s.h-1), 0)
+	}
+	s.cur.x = maxInt(minInt(x, s.w-1), 0)
+}
+
+// eraseDisplay erases below (0), above (1) or all (2, 3) of the screen
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for y := s.cur.y + 1; y < s.h; y++ {
+			s.cells[y] = s.blankRow()
+		}
+	case 1:
+		s.eraseLine(1)
+		for y := 0; y < s.cur.y; y++ {
+			s.cells[y] = s.blankRow()
+		}
+	case 2, 3:
+		for y := range s.cells {
+			s.cells[y] = s.blankRow()
+		}
+	}
+}
+
+// eraseLine erases right of (0), left of (1) or all (2) of the cursor row
+func (s *Screen) eraseLine(mode int) {
+	from, to := s.cur.x, s.w
+	switch mode {
+	case 1:
+		from, to = 0, s.cur.x+1
+	case 2:
+		from = 0
+	}
+	row := s.cells[s.cur.y]
+	for x := from; x < to; x++ {
+		row[x] = s.blankCell()
+	}
+}
+
+// setModes sets or resets ANSI modes, or DEC private modes
+func (s *Screen) setModes(p []int, private byte, on bool) {
+	for _, m := range p {
+		if private == 0 {
+			if m == 4 { // IRM
+				s.insert = on
+			}
+			continue
+		}
+		if private != '?' {
+			continue
+		}
+		switch m {
+		case 1: // DECCKM
+			s.appCursor = on
+		case 6: // DECOM
+			s.origin = on
+			s.moveTo(0, 0)
+		case 7: // DECAWM
+			s.autowrap = on
+		case 25: // DECTCEM
+			s.hidden = !on
+		case 47, 1047:
+			s.altScreen(on)
+		case 1048:
+			if on {
+				s.saved = cursor{x: s.cur.x, y: s.cur.y, attr: s.cur.attr, graphics: s.g0Graphics}
+			} else {
+				s.restoreCursor()
+			}
+		case 1049:
+			if on {
+				s.mainSaved = s.cur
+				s.altScreen(true)
+				s.eraseDisplay(2)
+			} else {
+				s.altScreen(false)
+				s.cur = s.mainSaved
+				s.cur.x, s.cur.y = minInt(s.cur.x, s.w-1), minInt(s.cur.y, s.h-1)
+			}
+		}
+	}
+}
+
+// altScreen switches to or from the alternate screen
+func (s *Screen) altScreen(on bool) {
+	if on && s.main == nil {
+		s.main = s.cells
+		s.cells = newGrid(s.w, s.h)
+	} else if !on && s.main != nil {
+		s.cells = s.main
+		s.main = nil
+	}
+	s.top, s.bot = 0, s.h-1
+}
+
+// sgr sets the rendition from SGR parameters
+func (s *Screen) sgr(p []int) {
+	if len(p) == 0 {
+		p = []int{0}
+	}
+	a := &s.cur.attr
+	for i := 0; i < len(p); i++ {
+		switch n := p[i]; {
+		case n == 0:
+			*a = Attr{}
+		case n == 1:
+			a.Bold = true
+		case n == 2:
+			a.Faint = true
+		case n == 3:
+			a.Italic = true
+		case n == 4:
+			a.Underline = true
+		case n == 5 || n == 6:
+			a.Blink = true
+		case n == 7:
+			a.Reverse = true
+		case n == 8:
+			a.Hidden = true
+		case n == 9:
+			a.Strike = true
+		case n == 21 || n == 22:
+			a.Bold, a.Faint = false, false
+		case n == 23:
+			a.Italic = false
+		case n == 24:
+			a.Underline = false
+		case n == 25:
+			a.Blink = false
+		case n == 27:
+			a.Reverse = false
+		case n == 28:
+			a.Hidden = false
+		case n == 29:
+			a.Strike = false
+		case n >= 30 && n <= 37:
+			a.Fg = Palette(n - 30)
+		case n == 38 || n == 48:
+			var c Color
+			c, i = extColor(p, i)
+			if n == 38 {
+				a.Fg = c
+			} else {
+				a.Bg = c
+			}
+		case n == 39:
+			a.Fg = 0
+		case n >= 40 && n <= 47:
+			a.Bg = Palette(n - 40)
+		case n == 49:
+			a.Bg = 0
+		case n >= 90 && n <= 97:
+			a.Fg = Palette(n - 90 + 8)
+		case n >= 100 && n <= 107:
+			a.Bg = Palette(n - 100 + 8)
+		}
+	}
+}
+
+// extColor parses a 38 or 48 extended color at p[i], returning it and the index of its last parameter
+func extColor(p []int, i int) (Color, int) {
+	if i+1 >= len(p) {
+		return 0, i
+	}
+	switch p[i+1] {
+	case 5:
+		if i+2 < len(p) {
+			return Palette(p[i+2] & 0xff), i + 2
+		}
+	case 2:
+		if i+4 < len(p) {
+			return RGB | Color(p[i+2]&0xff)<<16 | Color(p[i+3]&0xff)<<8 | Color(p[i+4]&0xff), i + 4
+		}
+	}
+	return 0, len(p)
+}
+
+// respond writes a reply to a status request
+func (s *Screen) respond(r string) {
+	if s.response != nil {
+		io.WriteString(s.response, r)
+	}
+}
@@ -0,0 +1,135 @@
+package vt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// screen returns a w x h Screen after writing out to it
+func screen(w, h int, out string) *Screen {
+	s := New(w, h)
+	s.Write([]byte(out))
+	return s
+}
+
+func checkScreen(t *testing.T, name string, s *Screen, expected ...string) {
+	got := strings.Split(s.String(), "\n")
+	for len(got) > 0 && got[len(got)-1] == "" {
+		got = got[:len(got)-1]
+	}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("%s expected: %q but got: %q", name, expected, got)
+	}
+}
+
+func checkCursor(t *testing.T, name string, s *Screen, x, y int) {
+	if cx, cy, _ := s.Cursor(); cx != x || cy != y {
+		t.Errorf("%s expected cursor: %d,%d but got: %d,%d", name, x, y, cx, cy)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	s := screen(5, 4, "hello world\r\nab\tc")
+	checkScreen(t, "wrap", s, "hello", " worl", "d", "ab  c")
+	s = screen(10, 2, "ab\tc\r\nxyz\bQ")
+	checkScreen(t, "tab bs", s, "ab      c", "xyQ")
+	s = screen(4, 2, "1\r\n2\r\n3\r\n4")
+	checkScreen(t, "scroll", s, "3", "4")
+	s = screen(6, 1, "日本x")
+	checkScreen(t, "wide", s, "日本x")
+	checkCursor(t, "wide", s, 5, 0)
+	s = screen(5, 2, "abcd日")
+	checkScreen(t, "wide wrap", s, "abcd", "日")
+	s = screen(5, 1, "ab\xc3")
+	s.Write([]byte("\xa9d"))
+	checkScreen(t, "split utf8", s, "abéd")
+}
+
+func TestCSI(t *testing.T) {
+	s := screen(6, 3, "abcdef\x1b[2;3HX\x1b[1;1H\x1b[2CY")
+	checkScreen(t, "cup", s, "abYdef", "  X")
+	s = screen(6, 2, "abcdef\x1b[1;3H\x1b[K")
+	checkScreen(t, "el", s, "ab")
+	s = screen(6, 2, "abcdef\x1b[1;3H\x1b[1K")
+	checkScreen(t, "el 1", s, "   def")
+	s = screen(6, 3, "aa\r\nbb\r\ncc\x1b[2;1H\x1b[J")
+	checkScreen(t, "ed", s, "aa")
+	s = screen(6, 1, "abcdef\x1b[1;2H\x1b[2P")
+	checkScreen(t, "dch", s, "adef")
+	s = screen(6, 1, "abcd\x1b[1;2H\x1b[2@")
+	checkScreen(t, "ich", s, "a  bcd")
+	s = screen(6, 3, "aa\r\nbb\r\ncc\x1b[2;1H\x1b[L")
+	checkScreen(t, "il", s, "aa", "", "bb")
+	s = screen(6, 3, "aa\r\nbb\r\ncc\x1b[1;1H\x1b[M")
+	checkScreen(t, "dl", s, "bb", "cc")
+	s = screen(6, 4, "11\r\n22\r\n33\r\n44\x1b[2;3r\x1b[3;1H\n\nxx")
+	checkScreen(t, "scroll region", s, "11", "", "xx", "44")
+	s = screen(6, 3, "aa\r\nbb\x1bMX\x1bMY")
+	checkScreen(t, "reverse index", s, "   Y", "aaX", "bb")
+}
+
+func TestSGR(t *testing.T) {
+	s := screen(10, 1, "\x1b[1;31ma\x1b[0;38;5;200;48;2;1;2;3mb\x1b[94mc\x1b[mD")
+	a := s.Cell(0, 0).Attr
+	if !a.Bold || a.Fg != Palette(1) {
+		t.Errorf("bold red got: %+v", a)
+	}
+	b := s.Cell(1, 0).Attr
+	if b.Bold || b.Fg != Palette(200) || b.Bg != RGB|0x010203 {
+		t.Errorf("extended got: %+v", b)
+	}
+	if c := s.Cell(2, 0).Attr; c.Fg != Palette(12) {
+		t.Errorf("bright got: %+v", c)
+	}
+	if d := s.Cell(3, 0).Attr; d != (Attr{}) {
+		t.Errorf("reset got: %+v", d)
+	}
+	if got := b.SGR(); got != "\x1b[0;38;5;200;48;2;1;2;3m" {
+		t.Errorf("sgr got: %q", got)
+	}
+	if got := a.SGR(); got != "\x1b[0;1;31m" {
+		t.Errorf("sgr got: %q", got)
+	}
+}
+
+func TestModes(t *testing.T) {
+	s := screen(6, 2, "main\x1b[?1049h\x1b[HALT")
+	checkScreen(t, "alt", s, "ALT")
+	s.Write([]byte("\x1b[?1049l"))
+	checkScreen(t, "alt off", s, "main")
+	checkCursor(t, "alt off", s, 4, 0)
+	s = screen(6, 2, "\x1b[?25l\x1b[?1h")
+	if _, _, visible := s.Cursor(); visible || !s.AppCursor() {
+		t.Errorf("modes expected hidden cursor and app cursor keys")
+	}
+	s = screen(6, 2, "\x1b(0lqk\x1b(Bq")
+	checkScreen(t, "graphics", s, "┌─┐q")
+	s = screen(6, 2, "\x1b]0;my title\x07ok\x1b]2;other\x1b\\")
+	checkScreen(t, "osc", s, "ok")
+	if s.Title() != "other" {
+		t.Errorf("title got: %q", s.Title())
+	}
+}
+
+func TestResponse(t *testing.T) {
+	var b bytes.Buffer
+	s := New(10, 5)
+	s.SetResponse(&b)
+	s.Write([]byte("\x1b[3;4H\x1b[6n\x1b[c"))
+	if b.String() != "\x1b[3;4R\x1b[?6c" {
+		t.Errorf("response got: %q", b.String())
+	}
+}
+
+func TestResize(t *testing.T) {
+	s := screen(6, 4, "11\r\n22\r\n33\r\n44")
+	s.Resize(3, 2)
+	checkScreen(t, "shrink", s, "33", "44")
+	checkCursor(t, "shrink", s, 2, 1)
+	s.Resize(8, 3)
+	checkScreen(t, "grow", s, "33", "44")
+	if w, h := s.Size(); w != 8 || h != 3 {
+		t.Errorf("size got: %d,%d", w, h)
+	}
+}
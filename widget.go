@@ -0,0 +1,356 @@
+package termfun
+
+// widget.go has render helpers for progress bars, gauges, spinners and sparklines,
+// and TileType_Widgets, which stacks Widgets top to bottom and renders them in place.
+// Widgets are safe to update from other goroutines, then call TileTerm.Render.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/exyzzy/termfun/format"
+)
+
+// block characters for sub cell precision
+const (
+	blockFull  = 0x2588 // full block
+	blockLeft  = 0x2590 // minus eighths, left partial blocks 0x2589 (7/8) to 0x258F (1/8)
+	blockLower = 0x2580 // plus eighths, lower partial blocks 0x2581 (1/8) to 0x2588 (8/8)
+)
+
+// SparkChars are the sparkline levels, lowest first
+var SparkChars = []rune("▁▂▃▄▅▆▇█")
+
+// maxSparkValues is the number of values a Sparkline keeps when Size is 0
+const maxSparkValues = 1024
+
+// spinner frame sets
+var (
+	SpinDots = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	SpinLine = []string{"|", "/", "-", "\\"}
+)
+
+// clamp01 limits f to 0..1
+func clamp01(f float64) float64 {
+	if f < 0 || math.IsNaN(f) {
+		return 0
+	}
+	if f > 1 {
+		return 1
+	}
+	return f
+}
+
+// Bar returns a horizontal bar width columns wide, filled to fraction 0..1 with eighth block precision
+func Bar(fraction float64, width int) string {
+	if width < 1 {
+		return ""
+	}
+	eighths := int(math.Round(clamp01(fraction) * float64(width*8)))
+	full, part := eighths/8, eighths%8
+	str := strings.Repeat(string(rune(blockFull)), full)
+	if part > 0 {
+		str += string(rune(blockLeft - part))
+		full++
+	}
+	return str + padSpaces(width-full)
+}
+
+// VBar returns a vertical bar height rows high, top row first, filled to fraction 0..1 from the bottom
+// with eighth block precision
+func VBar(fraction float64, height int) []string {
+	rows := make([]string, height)
+	eighths := int(math.Round(clamp01(fraction) * float64(height*8)))
+	for i := range rows {
+		fill := eighths - (height-1-i)*8 // eighths in this row
+		switch {
+		case fill >= 8:
+			rows[i] = string(rune(blockFull))
+		case fill > 0:
+			rows[i] = string(rune(blockLower + fill))
+		default:
+			rows[i] = " "
+		}
+	}
+	return rows
+}
+
+// Spark returns a sparkline of the last width values, scaled from the smallest to the largest of them
+func Spark(values []float64, width int) string {
+	if width < 1 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	if len(values) == 0 {
+		return padSpaces(width)
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	var b strings.Builder
+	top := len(SparkChars) - 1
+	for _, v := range values {
+		level := top / 2
+		if hi > lo {
+			level = int(math.Round((v - lo) / (hi - lo) * float64(top)))
+		}
+		b.WriteRune(SparkChars[level])
+	}
+	return b.String() + padSpaces(width-len(values))
+}
+
+// Widget is rendered by a TileType_Widgets tile
+// Lines returns the rendered rows, each width columns wide
+type Widget interface {
+	Lines(width int) []string
+}
+
+// labelled returns label, then body filling the rest of width, then suffix
+func labelled(label string, width int, suffix string, body func(int) string) string {
+	if label != "" {
+		label += " "
+	}
+	if suffix != "" {
+		suffix = " " + suffix
+	}
+	w := width - format.StringWidth(label) - format.StringWidth(suffix)
+	if w < 1 { // no room for the body
+		return format.FormatTextClipCol(label+suffix, width, 1, 0)[0]
+	}
+	return label + body(w) + suffix
+}
+
+// ProgressBar is a horizontal, or vertical, progress bar Widget
+// A vertical bar is Height rows high with the label and percentage on the bottom row
+type ProgressBar struct {
+	Label    string
+	Vertical bool
+	Height   int
+	value    float64
+	lock     sync.Mutex
+}
+
+// NewProgressBar returns a new horizontal ProgressBar
+func NewProgressBar(label string) *ProgressBar {
+	return &ProgressBar{Label: label}
+}
+
+// NewVProgressBar returns a new vertical ProgressBar height rows high
+func NewVProgressBar(label string, height int) *ProgressBar {
+	return &ProgressBar{Label: label, Vertical: true, Height: height}
+}
+
+// Set sets the progress, 0 to 1
+func (p *ProgressBar) Set(fraction float64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.value = clamp01(fraction)
+}
+
+// Value returns the progress, 0 to 1
+func (p *ProgressBar) Value() float64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.value
+}
+
+// Lines renders the ProgressBar
+func (p *ProgressBar) Lines(width int) []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	pct := fmt.Sprintf("%3.0f%%", p.value*100)
+	if !p.Vertical {
+		return []string{labelled(p.Label, width, pct, func(w int) string { return Bar(p.value, w) })}
+	}
+	rows := VBar(p.value, p.Height)
+	for i := range rows {
+		text := ""
+		if i == len(rows)-1 {
+			text = strings.TrimSpace(p.Label + " " + pct)
+		}
+		rows[i] = format.FormatTextClipCol(rows[i]+" "+text, width, 1, 0)[0]
+	}
+	return rows
+}
+
+// Gauge is a labelled value Widget with a bar, colored by the Warn and Crit thresholds
+type Gauge struct {
+	Label string
+	Max   float64
+	Unit  string
+	Warn  float64 // value at which the bar is yellow, or 0 for none
+	Crit  float64 // value at which the bar is red, or 0 for none
+	value float64
+	lock  sync.Mutex
+}
+
+// NewGauge returns a new Gauge from 0 to max
+func NewGauge(label string, max float64, unit string) *Gauge {
+	return &Gauge{Label: label, Max: max, Unit: unit}
+}
+
+// Set sets the value of the Gauge
+func (g *Gauge) Set(value float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.value = value
+}
+
+// Lines renders the Gauge
+func (g *Gauge) Lines(width int) []string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	color := SGR_FgGreen
+	switch {
+	case g.Crit > 0 && g.value >= g.Crit:
+		color = SGR_FgRed
+	case g.Warn > 0 && g.value >= g.Warn:
+		color = SGR_FgYellow
+	}
+	text := fmt.Sprintf("%g/%g%s", g.value, g.Max, g.Unit)
+	return []string{labelled(g.Label, width, text, func(w int) string {
+		var fraction float64
+		if g.Max > 0 {
+			fraction = g.value / g.Max
+		}
+		return SGR(color) + Bar(fraction, w) + SGR(SGR_FgDefault)
+	})}
+}
+
+// Spinner is a Widget that shows the next of its Frames on each Tick
+type Spinner struct {
+	Label  string
+	Frames []string
+	Done   string // shown instead of the frames when stopped, or ""
+	frame  int
+	done   bool
+	lock   sync.Mutex
+}
+
+// NewSpinner returns a new Spinner using frames, or SpinDots if there are none
+func NewSpinner(label string, frames ...string) *Spinner {
+	if len(frames) == 0 {
+		frames = SpinDots
+	}
+	return &Spinner{Label: label, Frames: frames, Done: "✓"}
+}
+
+// Tick advances the Spinner to its next frame
+func (s *Spinner) Tick() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.frame++
+}
+
+// Stop shows Done instead of the frames, with a new label if label is not ""
+func (s *Spinner) Stop(label string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.done = true
+	if label != "" {
+		s.Label = label
+	}
+}
+
+// Lines renders the Spinner
+func (s *Spinner) Lines(width int) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	frame := s.Done
+	if !s.done && len(s.Frames) > 0 {
+		frame = s.Frames[s.frame%len(s.Frames)]
+	}
+	return []string{format.FormatTextClipCol(frame+" "+s.Label, width, 1, 0)[0]}
+}
+
+// Sparkline is a Widget that shows the most recent values as a sparkline, with the last value
+type Sparkline struct {
+	Label  string
+	Size   int // values kept, or 0 for maxSparkValues
+	Format string
+	values []float64
+	lock   sync.Mutex
+}
+
+// NewSparkline returns a new Sparkline keeping size values, showing the last value with valueFormat, like "%.1f"
+func NewSparkline(label string, size int, valueFormat string) *Sparkline {
+	return &Sparkline{Label: label, Size: size, Format: valueFormat}
+}
+
+// Add appends a value, dropping the oldest beyond Size
+func (s *Sparkline) Add(v float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.values = append(s.values, v)
+	size := s.Size
+	if size < 1 {
+		size = maxSparkValues
+	}
+	if len(s.values) > size {
+		s.values = append(s.values[:0], s.values[len(s.values)-size:]...)
+	}
+}
+
+// Lines renders the Sparkline
+func (s *Sparkline) Lines(width int) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var last string
+	if len(s.values) > 0 && s.Format != "" {
+		last = fmt.Sprintf(s.Format, s.values[len(s.values)-1])
+	}
+	return []string{labelled(s.Label, width, last, func(w int) string { return Spark(s.values, w) })}
+}
+
+// AddWidget adds a Widget below the others in a TileType_Widgets tile
+func (t *Tile) AddWidget(w Widget) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.handler.TileType != TileType_Widgets {
+		return errors.New("Handler.TileType does not support Widgets")
+	}
+	t.widgets = append(t.widgets, w)
+	t.dirty = true
+	return nil
+}
+
+// RemoveWidget removes a Widget from a TileType_Widgets tile
+func (t *Tile) RemoveWidget(w Widget) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for i, tw := range t.widgets {
+		if tw == w {
+			t.widgets = append(t.widgets[:i], t.widgets[i+1:]...)
+			break
+		}
+	}
+	t.dirty = true
+}
+
+// == TileType_Widgets Handler Functions
+func wg_RenderText(t *Tile) string {
+	t.lock.Lock()
+	widgets := append([]Widget(nil), t.widgets...)
+	t.lock.Unlock()
+	var lines []string
+	for _, w := range widgets {
+		for _, line := range w.Lines(t.Width()) {
+			lines = append(lines, line+SGR(SGR_Off))
+		}
+	}
+	t.lineCount = len(lines)
+	t.scrollClamp()
+	return renderLinesDown(t, lines, sd_SetCurPosOrigin)
+}
+
+// wg_KeyPress scrolls when the widgets are taller than the tile
+func wg_KeyPress(t *Tile, r rune) bool {
+	t.scrollKey(r)
+	return false
+}